@@ -1,18 +1,21 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"github.com/joho/godotenv"
+	"github.com/m4rk1sov/rbk-api/internal/cache"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
 	"github.com/m4rk1sov/rbk-api/internal/handler"
 	"github.com/m4rk1sov/rbk-api/internal/repository"
 	"github.com/m4rk1sov/rbk-api/internal/service"
 	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
 	"io"
 	"log"
-	_ "modernc.org/sqlite"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,8 +25,31 @@ func main() {
 	addr := getenv("ADDR", ":8080")
 	wgerBase := getenv("WGER_BASE_URL", "https://wger.de/api/v2")
 	lang := getenvInt("WGER_LANGUAGE", 2)
+	schema := getenv("WGER_SCHEMA", string(repository.SchemaExerciseInfo))
+	maxPages := getenvInt("WGER_MAX_PAGES", 5)
 	ua := getenv("HTTP_USER_AGENT", "rbk-api/1.0 (+https://github.com/m4rk1sov/rbk-api)")
 	similarPath := getenv("SIMILAR_MUSCLES_FILE", "./similar_muscles.json")
+	dbPath := getenv("DB_PATH", "./rbk.db")
+	muscleSync := getenvDuration("MUSCLE_SYNC_INTERVAL", 24*time.Hour)
+	cacheEntries := getenvInt("CACHE_MAX_ENTRIES", 500)
+	redisURL := os.Getenv("REDIS_URL")
+	adviceProviders := getenv("ADVICE_PROVIDERS", "adviceslip,file")
+	adviceFile := getenv("ADVICE_FILE", "./fitness_tips.yaml")
+	adviceTTL := getenvDuration("ADVICE_CACHE_TTL", 30*time.Second)
+	adviceRules := getenv("ADVICE_RULES_FILE", "./advice_rules.yaml")
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	apiKeysFile := os.Getenv("API_KEYS_FILE")
+	apiKeysRequired := getenvBool("API_KEYS_REQUIRED", false)
+	keyRate := getenvFloat("RATE_LIMIT_KEY_RPS", 10)
+	keyBurst := getenvInt("RATE_LIMIT_KEY_BURST", 40)
+	ipRate := getenvFloat("RATE_LIMIT_IP_RPS", 5)
+	ipBurst := getenvInt("RATE_LIMIT_IP_BURST", 20)
+	trustProxy := getenvBool("TRUST_PROXY", false)
+	wgerRate := getenvFloat("WGER_RATE_LIMIT_RPS", 5)
+	wgerBurst := getenvInt("WGER_RATE_LIMIT_BURST", 10)
+	wgerRetries := getenvInt("WGER_MAX_RETRIES", 2)
+	breakerThreshold := getenvInt("WGER_BREAKER_THRESHOLD", 5)
+	breakerCooldown := getenvDuration("WGER_BREAKER_COOLDOWN", 30*time.Second)
 
 	logFile, err := os.OpenFile("logs.txt", os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
 	if err != nil {
@@ -38,10 +64,99 @@ func main() {
 
 	logger := jsonlog.New(io.MultiWriter(os.Stdout, logFile), jsonlog.LevelInfo)
 
-	httpClient := &http.Client{Timeout: 10 * time.Second}
-	client := repository.NewWgerClient(httpClient, wgerBase, lang, ua)
-	svc := service.NewFitnessService(client, logger, similarPath)
-	h := handler.New(svc, logger)
+	// the timeout covers retries too, so a slow wger can't hold a request longer than this
+	upstream := repository.NewTransport(http.DefaultTransport, repository.TransportConfig{
+		RateLimit:        wgerRate,
+		Burst:            wgerBurst,
+		MaxRetries:       wgerRetries,
+		FailureThreshold: breakerThreshold,
+		OpenTimeout:      breakerCooldown,
+	})
+	httpClient := &http.Client{Timeout: 15 * time.Second, Transport: upstream}
+	wgerSchema, ok := repository.ParseExerciseSchema(schema)
+	if !ok {
+		logger.PrintFatal("invalid WGER_SCHEMA, want exercise or exerciseinfo", map[string]string{"schema": schema})
+	}
+	client := repository.NewWgerClient(httpClient, wgerBase, lang, ua).
+		WithMaxPages(maxPages).
+		WithSchema(wgerSchema).
+		WithLogger(logger)
+
+	// the SQLite mirror is optional: without it we only lose the offline fallback
+	var store *repository.ExerciseStore
+	var users *service.UserService
+	var keys repository.KeyChain
+	db, err := repository.OpenSQLite(dbPath)
+	if err != nil {
+		logger.PrintError("failed to open database", map[string]string{"path": dbPath, "error": err.Error()})
+	} else {
+		defer func() { _ = db.Close() }()
+		if store, err = repository.NewExerciseStore(context.Background(), db); err != nil {
+			logger.PrintError("failed to prepare exercise store", map[string]string{"error": err.Error()})
+		}
+		// accounts live in the same database; without it the account routes are disabled
+		if userStore, err := repository.NewUserStore(context.Background(), db); err != nil {
+			logger.PrintError("failed to prepare user store", map[string]string{"error": err.Error()})
+		} else {
+			users = service.NewUserService(userStore)
+			keys = append(keys, userStore)
+		}
+	}
+
+	svc := service.NewFitnessService(client, store, logger, similarPath).
+		WithCacheSize(cacheEntries).
+		WithRules(service.NewRulesEngine(adviceRules, logger))
+	// with REDIS_URL set, replicas share one exercise cache instead of each warming its own
+	if redisURL != "" {
+		shared, err := cache.NewRESP[[]models.Exercise](cache.RESPConfig{
+			URL:       redisURL,
+			Namespace: "rbk:exercises:",
+			TTL:       svc.CacheMaxAge(),
+		})
+		if err != nil {
+			logger.PrintFatal("invalid REDIS_URL", map[string]string{"error": err.Error()})
+		}
+		defer func() { _ = shared.Close() }()
+		svc.WithCacheStore(shared)
+	}
+	go svc.Muscles().Run(context.Background(), muscleSync)
+	if users != nil {
+		users.WithMuscles(svc.Muscles())
+	}
+	advice, tips := buildAdvice(adviceProviders, adviceFile, logger)
+	cachedAdvice := service.NewCachedProvider(advice, adviceTTL)
+	if tips != nil {
+		svc.WithTips(service.NewCachedProvider(tips, adviceTTL))
+	}
+
+	if apiKeysFile != "" {
+		fileKeys, err := repository.NewFileKeys(apiKeysFile)
+		if err != nil {
+			logger.PrintFatal("failed to load API keys", map[string]string{"path": apiKeysFile, "error": err.Error()})
+		}
+		logger.PrintInfo("API keys loaded", map[string]string{"path": apiKeysFile, "keys": strconv.Itoa(fileKeys.Len())})
+		keys = append(keys, fileKeys)
+	}
+	// user tokens and file keys both count as API keys; limits apply with or without API_KEYS_REQUIRED.
+	// /admin sends ADMIN_TOKEN in the same Authorization header, so it skips key checks and is
+	// guarded by the token alone (disabled when ADMIN_TOKEN is unset) and the per-IP limit.
+	apiKeys := repository.NewAPIKeys(repository.APIKeyConfig{
+		Keys:       keys,
+		Required:   apiKeysRequired,
+		Public:     []string{"/healthz", "/docs", "/redoc", "/swagger.yaml", "/users", "/admin"},
+		PerKey:     repository.NewRateLimiter(keyRate, keyBurst),
+		PerIP:      repository.NewRateLimiter(ipRate, ipBurst),
+		TrustProxy: trustProxy,
+		Logger:     logger,
+	})
+
+	h := handler.New(svc, logger, handler.Config{
+		AdminToken: adminToken,
+		Advice:     cachedAdvice,
+		Users:      users,
+		APIKeys:    apiKeys,
+		Upstream:   upstream,
+	})
 
 	logger.PrintInfo("starting server", map[string]string{"addr": addr})
 	if err := http.ListenAndServe(addr, h.Router()); err != nil {
@@ -49,6 +164,31 @@ func main() {
 	}
 }
 
+// buildAdvice chains the providers named in the comma-separated list, in order. The curated
+// tips provider is returned separately (nil when not loaded) to feed exercise responses.
+func buildAdvice(names, tipsFile string, logger *jsonlog.Logger) (service.ChainProvider, *service.FileProvider) {
+	var chain service.ChainProvider
+	var tips *service.FileProvider
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "adviceslip":
+			chain = append(chain, service.NewSlipProvider(&http.Client{Timeout: 10 * time.Second}, service.AdviceSlipURL))
+		case "file":
+			p, err := service.NewFileProvider(tipsFile)
+			if err != nil {
+				logger.PrintError("failed to load advice file", map[string]string{"path": tipsFile, "error": err.Error()})
+				continue
+			}
+			chain = append(chain, p)
+			tips = p
+		case "":
+		default:
+			logger.PrintError("unknown advice provider", map[string]string{"provider": name})
+		}
+	}
+	return chain, tips
+}
+
 func getenv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -64,3 +204,30 @@ func getenvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getenvFloat(key string, fallback float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+func getenvBool(key string, fallback bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+func getenvDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
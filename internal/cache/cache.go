@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a cache backend. Implementations must be safe for concurrent use.
+type Store[V any] interface {
+	// Get returns the value for key and when it was stored.
+	Get(ctx context.Context, key string) (V, time.Time, bool, error)
+	Set(ctx context.Context, key string, value V) error
+	// Delete removes key and reports whether it was present.
+	Delete(ctx context.Context, key string) (bool, error)
+	// DeletePrefix removes every key starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+	// Purge removes every entry owned by this store.
+	Purge(ctx context.Context) (int, error)
+	Stats() Stats
+}
+
+// Memory is an in-process Store backed by an LRU.
+type Memory[V any] struct {
+	lru *LRU[V]
+}
+
+// NewMemory creates an in-process store holding at most capacity entries for up to maxAge.
+func NewMemory[V any](capacity int, maxAge time.Duration) *Memory[V] {
+	return &Memory[V]{lru: NewLRU[V](capacity, maxAge)}
+}
+
+func (m *Memory[V]) Get(_ context.Context, key string) (V, time.Time, bool, error) {
+	v, storedAt, ok := m.lru.Get(key)
+	return v, storedAt, ok, nil
+}
+
+func (m *Memory[V]) Set(_ context.Context, key string, value V) error {
+	m.lru.Set(key, value)
+	return nil
+}
+
+func (m *Memory[V]) Delete(_ context.Context, key string) (bool, error) {
+	return m.lru.Delete(key), nil
+}
+
+func (m *Memory[V]) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	return m.lru.DeletePrefix(prefix), nil
+}
+
+func (m *Memory[V]) Purge(_ context.Context) (int, error) {
+	return m.lru.Purge(), nil
+}
+
+func (m *Memory[V]) Stats() Stats {
+	return m.lru.Stats()
+}
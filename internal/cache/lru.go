@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats are cumulative counters for a cache.
+type Stats struct {
+	Entries     int    `json:"entries"`
+	Capacity    int    `json:"capacity"`
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	// Errors counts failed backend calls (remote stores only).
+	Errors  uint64 `json:"errors,omitempty"`
+	Backend string `json:"backend"`
+}
+
+// LRU is a size-bounded, least-recently-used cache. Entries older than maxAge are dropped on access.
+type LRU[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	maxAge   time.Duration
+	ll       *list.List
+	items    map[string]*list.Element
+
+	hits, misses, evictions, expirations uint64
+}
+
+type lruEntry[V any] struct {
+	key      string
+	value    V
+	storedAt time.Time
+}
+
+// NewLRU creates a cache holding at most capacity entries; maxAge <= 0 keeps entries until evicted.
+func NewLRU[V any](capacity int, maxAge time.Duration) *LRU[V] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRU[V]{
+		capacity: capacity,
+		maxAge:   maxAge,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value for key and when it was stored, marking it as recently used.
+func (c *LRU[V]) Get(key string) (V, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return zero, time.Time{}, false
+	}
+	e := el.Value.(*lruEntry[V])
+	if c.maxAge > 0 && time.Since(e.storedAt) >= c.maxAge {
+		c.removeElement(el)
+		c.expirations++
+		c.misses++
+		return zero, time.Time{}, false
+	}
+	c.ll.MoveToFront(el)
+	c.hits++
+	return e.value, e.storedAt, true
+}
+
+// Set stores value under key, evicting the least recently used entry when full.
+func (c *LRU[V]) Set(key string, value V) {
+	c.SetAt(key, value, time.Now())
+}
+
+// SetAt stores value as if it had been stored at storedAt.
+func (c *LRU[V]) SetAt(key string, value V, storedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry[V])
+		e.value, e.storedAt = value, storedAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry[V]{key: key, value: value, storedAt: storedAt})
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+		c.evictions++
+	}
+}
+
+// Delete removes key and reports whether it was present.
+func (c *LRU[V]) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if ok {
+		c.removeElement(el)
+	}
+	return ok
+}
+
+// DeletePrefix removes every key starting with prefix and returns how many were removed.
+func (c *LRU[V]) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+			n++
+		}
+	}
+	return n
+}
+
+// Purge removes every entry and returns how many there were.
+func (c *LRU[V]) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.ll.Len()
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	return n
+}
+
+// Stats returns a snapshot of the counters.
+func (c *LRU[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Entries:     c.ll.Len(),
+		Capacity:    c.capacity,
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		Expirations: c.expirations,
+		Backend:     "memory",
+	}
+}
+
+func (c *LRU[V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry[V]).key)
+}
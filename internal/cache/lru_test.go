@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[int](2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, _, ok := c.Get("a"); !ok { // a is now most recently used
+		t.Fatalf("expected a")
+	}
+	c.Set("c", 3)
+
+	if _, _, ok := c.Get("b"); ok {
+		t.Fatalf("b should have been evicted")
+	}
+	if v, _, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("expected c=3, got %v %v", v, ok)
+	}
+	st := c.Stats()
+	if st.Entries != 2 || st.Evictions != 1 || st.Hits != 2 || st.Misses != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestLRU_ExpiresAndDeletesByPrefix(t *testing.T) {
+	c := NewLRU[string](10, time.Minute)
+	c.SetAt("old", "x", time.Now().Add(-2*time.Minute))
+	c.Set("chest", "y")
+	c.Set("chest|c=11,e=0", "z")
+	c.Set("chestnut", "w")
+
+	if _, _, ok := c.Get("old"); ok {
+		t.Fatalf("expired entry returned")
+	}
+	if st := c.Stats(); st.Expirations != 1 || st.Entries != 3 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	if n := c.DeletePrefix("chest|"); n != 1 {
+		t.Fatalf("DeletePrefix removed %d, want 1", n)
+	}
+	if !c.Delete("chest") || c.Delete("chest") {
+		t.Fatalf("Delete should report presence once")
+	}
+	if n := c.Purge(); n != 1 {
+		t.Fatalf("Purge removed %d, want 1", n)
+	}
+}
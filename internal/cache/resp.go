@@ -0,0 +1,353 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RESPConfig configures a Redis-protocol store.
+type RESPConfig struct {
+	// URL is redis://[[user]:password@]host[:port][/db]; a user is sent along for Redis 6 ACLs.
+	URL string
+	// Namespace prefixes every key so several caches can share one server.
+	Namespace string
+	// TTL is applied to every key on the server; <= 0 keeps keys until deleted.
+	TTL      time.Duration
+	PoolSize int
+}
+
+// RESP is a Store backed by any server speaking the Redis serialization protocol,
+// so replicas share one warm cache. Values are stored as JSON.
+type RESP[V any] struct {
+	addr      string
+	user      string
+	password  string
+	db        int
+	namespace string
+	ttl       time.Duration
+	pool      chan *respConn
+
+	hits, misses, errs atomic.Uint64
+}
+
+// respTimeout bounds a single round trip when the caller's context has no deadline.
+const respTimeout = 2 * time.Second
+
+func NewRESP[V any](cfg RESPConfig) (*RESP[V], error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("invalid redis url %q", cfg.URL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "6379"
+	}
+	s := &RESP[V]{
+		addr:      net.JoinHostPort(u.Hostname(), port),
+		namespace: cfg.Namespace,
+		ttl:       cfg.TTL,
+	}
+	if pw, ok := u.User.Password(); ok {
+		s.user, s.password = u.User.Username(), pw
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if s.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis db %q", db)
+		}
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 8
+	}
+	s.pool = make(chan *respConn, cfg.PoolSize)
+	return s, nil
+}
+
+type respEnvelope[V any] struct {
+	Value    V     `json:"v"`
+	StoredAt int64 `json:"t"`
+}
+
+func (s *RESP[V]) Get(ctx context.Context, key string) (V, time.Time, bool, error) {
+	var zero V
+	reply, err := s.do(ctx, "GET", s.namespace+key)
+	if err != nil {
+		return zero, time.Time{}, false, err
+	}
+	b, ok := reply.([]byte)
+	if !ok {
+		s.misses.Add(1)
+		return zero, time.Time{}, false, nil
+	}
+	var env respEnvelope[V]
+	if err := json.Unmarshal(b, &env); err != nil {
+		s.errs.Add(1)
+		return zero, time.Time{}, false, err
+	}
+	s.hits.Add(1)
+	return env.Value, time.Unix(0, env.StoredAt), true, nil
+}
+
+func (s *RESP[V]) Set(ctx context.Context, key string, value V) error {
+	b, err := json.Marshal(respEnvelope[V]{Value: value, StoredAt: time.Now().UnixNano()})
+	if err != nil {
+		return err
+	}
+	args := []string{"SET", s.namespace + key, string(b)}
+	if s.ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(s.ttl.Milliseconds(), 10))
+	}
+	_, err = s.do(ctx, args...)
+	return err
+}
+
+func (s *RESP[V]) Delete(ctx context.Context, key string) (bool, error) {
+	reply, err := s.do(ctx, "DEL", s.namespace+key)
+	if err != nil {
+		return false, err
+	}
+	n, _ := reply.(int64)
+	return n > 0, nil
+}
+
+func (s *RESP[V]) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	pattern := escapeGlob(s.namespace+prefix) + "*"
+	removed := 0
+	cursor := "0"
+	for {
+		reply, err := s.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", "100")
+		if err != nil {
+			return removed, err
+		}
+		parts, ok := reply.([]any)
+		if !ok || len(parts) != 2 {
+			return removed, errors.New("unexpected SCAN reply")
+		}
+		next, _ := parts[0].([]byte)
+		keys, _ := parts[1].([]any)
+		if len(keys) > 0 {
+			args := make([]string, 0, len(keys)+1)
+			args = append(args, "DEL")
+			for _, k := range keys {
+				if b, ok := k.([]byte); ok {
+					args = append(args, string(b))
+				}
+			}
+			reply, err := s.do(ctx, args...)
+			if err != nil {
+				return removed, err
+			}
+			n, _ := reply.(int64)
+			removed += int(n)
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return removed, nil
+		}
+	}
+}
+
+func (s *RESP[V]) Purge(ctx context.Context) (int, error) {
+	return s.DeletePrefix(ctx, "")
+}
+
+func (s *RESP[V]) Stats() Stats {
+	return Stats{
+		Hits:    s.hits.Load(),
+		Misses:  s.misses.Load(),
+		Errors:  s.errs.Load(),
+		Backend: "resp",
+	}
+}
+
+// Close closes idle connections.
+func (s *RESP[V]) Close() error {
+	var err error
+	for {
+		select {
+		case c := <-s.pool:
+			err = errors.Join(err, c.Close())
+		default:
+			return err
+		}
+	}
+}
+
+// respError is an error reply sent by the server.
+type respError string
+
+func (e respError) Error() string { return "resp: " + string(e) }
+
+type respConn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// do sends one command and reads its reply. Connections that fail mid-command are discarded.
+func (s *RESP[V]) do(ctx context.Context, args ...string) (any, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		s.errs.Add(1)
+		return nil, err
+	}
+	reply, err := c.roundTrip(ctx, args...)
+	var serverErr respError
+	if err != nil && !errors.As(err, &serverErr) {
+		_ = c.Close()
+		s.errs.Add(1)
+		return nil, err
+	}
+	s.release(c)
+	if err != nil {
+		s.errs.Add(1)
+	}
+	return reply, err
+}
+
+func (s *RESP[V]) conn(ctx context.Context) (*respConn, error) {
+	select {
+	case c := <-s.pool:
+		return c, nil
+	default:
+	}
+	d := net.Dialer{Timeout: respTimeout}
+	nc, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &respConn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if s.password != "" {
+		auth := []string{"AUTH", s.password}
+		if s.user != "" {
+			auth = []string{"AUTH", s.user, s.password}
+		}
+		if _, err := c.roundTrip(ctx, auth...); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	if s.db != 0 {
+		if _, err := c.roundTrip(ctx, "SELECT", strconv.Itoa(s.db)); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (s *RESP[V]) release(c *respConn) {
+	select {
+	case s.pool <- c:
+	default:
+		_ = c.Close()
+	}
+}
+
+func (c *respConn) roundTrip(ctx context.Context, args ...string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(respTimeout)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if err := writeCommand(c.w, args); err != nil {
+		return nil, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+func writeCommand(w *bufio.Writer, args []string) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, a := range args {
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(a), a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readReply parses one RESP2 value: simple strings become string, bulk strings []byte,
+// integers int64, arrays []any, nil bulk/array nil and error replies respError.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("resp: empty reply line")
+	}
+	body := line[1:]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, respError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		out := make([]any, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := readReply(r)
+			var serverErr respError
+			if err != nil && !errors.As(err, &serverErr) {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("resp: unknown reply type %q", line[0])
+	}
+}
+
+func escapeGlob(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRESP is an in-process server understanding the handful of commands RESP uses.
+type fakeRESP struct {
+	mu       sync.Mutex
+	data     map[string]string
+	user     string
+	password string
+	commands []string
+}
+
+func startFakeRESP(t *testing.T, password string) (*fakeRESP, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	f := &fakeRESP{data: map[string]string{}, password: password}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeRESP) serve(c net.Conn) {
+	defer func() { _ = c.Close() }()
+	r := bufio.NewReader(c)
+	authed := f.password == ""
+	for {
+		v, err := readReply(r)
+		if err != nil {
+			return
+		}
+		parts, _ := v.([]any)
+		args := make([]string, len(parts))
+		for i, p := range parts {
+			args[i] = string(p.([]byte))
+		}
+		if len(args) == 0 {
+			return
+		}
+		cmd := strings.ToUpper(args[0])
+
+		f.mu.Lock()
+		f.commands = append(f.commands, cmd)
+		var reply string
+		switch {
+		case cmd == "AUTH":
+			authed = len(args) == 2 && f.user == "" && args[1] == f.password ||
+				len(args) == 3 && args[1] == f.user && args[2] == f.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "SELECT" || cmd == "PING":
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			if val, ok := f.data[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(val), val)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case cmd == "SET":
+			f.data[args[1]] = args[2]
+			reply = "+OK\r\n"
+		case cmd == "DEL":
+			n := 0
+			for _, k := range args[1:] {
+				if _, ok := f.data[k]; ok {
+					delete(f.data, k)
+					n++
+				}
+			}
+			reply = fmt.Sprintf(":%d\r\n", n)
+		case cmd == "SCAN":
+			var keys []string
+			for k := range f.data {
+				if ok, _ := path.Match(args[3], k); ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			reply = fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+			for _, k := range keys {
+				reply += fmt.Sprintf("$%d\r\n%s\r\n", len(k), k)
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := c.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func TestRESP_RoundTripAndPrefixDelete(t *testing.T) {
+	fake, addr := startFakeRESP(t, "s3cret")
+	ctx := context.Background()
+
+	store, err := NewRESP[[]int](RESPConfig{URL: "redis://:s3cret@" + addr + "/2", Namespace: "rbk:"})
+	if err != nil {
+		t.Fatalf("NewRESP: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, _, ok, err := store.Get(ctx, "chest"); err != nil || ok {
+		t.Fatalf("expected miss, got ok=%v err=%v", ok, err)
+	}
+	for _, key := range []string{"chest", "chest|c=11,e=0", "back"} {
+		if err := store.Set(ctx, key, []int{4, 5}); err != nil {
+			t.Fatalf("Set(%s): %v", key, err)
+		}
+	}
+	got, storedAt, ok, err := store.Get(ctx, "chest")
+	if err != nil || !ok || len(got) != 2 || got[1] != 5 || storedAt.IsZero() {
+		t.Fatalf("Get: %v %v %v %v", got, storedAt, ok, err)
+	}
+
+	if n, err := store.DeletePrefix(ctx, "chest|"); err != nil || n != 1 {
+		t.Fatalf("DeletePrefix: n=%d err=%v", n, err)
+	}
+	if ok, err := store.Delete(ctx, "chest"); err != nil || !ok {
+		t.Fatalf("Delete: %v %v", ok, err)
+	}
+
+	// keys outside the namespace survive a purge
+	fake.mu.Lock()
+	fake.data["other:key"] = "x"
+	fake.mu.Unlock()
+	if n, err := store.Purge(ctx); err != nil || n != 1 {
+		t.Fatalf("Purge: n=%d err=%v", n, err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if _, ok := fake.data["other:key"]; !ok || len(fake.data) != 1 {
+		t.Fatalf("purge touched foreign keys: %v", fake.data)
+	}
+	if fake.commands[0] != "AUTH" || fake.commands[1] != "SELECT" {
+		t.Fatalf("expected AUTH and SELECT on connect, got %v", fake.commands[:2])
+	}
+
+	st := store.Stats()
+	if st.Hits != 1 || st.Misses != 1 || st.Errors != 0 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestRESP_WrongPassword(t *testing.T) {
+	_, addr := startFakeRESP(t, "s3cret")
+	store, err := NewRESP[string](RESPConfig{URL: "redis://:nope@" + addr})
+	if err != nil {
+		t.Fatalf("NewRESP: %v", err)
+	}
+	if _, _, _, err := store.Get(context.Background(), "k"); err == nil {
+		t.Fatalf("expected auth error")
+	}
+	if st := store.Stats(); st.Errors != 1 {
+		t.Fatalf("expected error to be counted, got %+v", st)
+	}
+}
+
+func TestNewRESP_Address(t *testing.T) {
+	tests := map[string]string{
+		"redis://cache":          "cache:6379",
+		"redis://cache:6380/1":   "cache:6380",
+		"redis://[::1]":          "[::1]:6379",
+		"redis://[::1]:7000":     "[::1]:7000",
+		"redis://:pw@10.0.0.5/2": "10.0.0.5:6379",
+	}
+	for raw, want := range tests {
+		store, err := NewRESP[string](RESPConfig{URL: raw})
+		if err != nil {
+			t.Errorf("NewRESP(%q): %v", raw, err)
+			continue
+		}
+		if store.addr != want {
+			t.Errorf("NewRESP(%q): addr %q, want %q", raw, store.addr, want)
+		}
+	}
+}
+
+func TestRESP_ACLUser(t *testing.T) {
+	fake, addr := startFakeRESP(t, "s3cret")
+	fake.mu.Lock()
+	fake.user = "app"
+	fake.mu.Unlock()
+	store, err := NewRESP[string](RESPConfig{URL: "redis://app:s3cret@" + addr})
+	if err != nil {
+		t.Fatalf("NewRESP: %v", err)
+	}
+	if err := store.Set(context.Background(), "k", "v"); err != nil {
+		t.Fatalf("Set with ACL user: %v", err)
+	}
+}
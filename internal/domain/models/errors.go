@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrUnknownMuscle means the requested muscle name or alias does not exist.
+	ErrUnknownMuscle = errors.New("unknown muscle group")
+	// ErrInvalidIDs means numeric muscle IDs were given but are malformed or out of range.
+	ErrInvalidIDs = errors.New("invalid muscle ids")
+	// ErrUpstreamUnavailable means wger could not be reached or refused to serve the request.
+	ErrUpstreamUnavailable = errors.New("wger is unavailable")
+	// ErrUpstreamTimeout means wger did not answer in time.
+	ErrUpstreamTimeout = errors.New("wger timed out")
+	// ErrUpstreamBadResponse means wger answered with a body we could not decode.
+	ErrUpstreamBadResponse = errors.New("wger returned an invalid response")
+
+	// ErrCircuitOpen means wger calls are being short-circuited after repeated failures.
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUpstreamUnavailable)
+
+	// ErrNotFound means a user-owned resource (workout, favourite, token) does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrEmailTaken means a user with that email is already registered.
+	ErrEmailTaken = errors.New("email is already registered")
+	// ErrInvalidCredentials means a login or API token was rejected.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrInvalidPlan means a split plan request can't be satisfied.
+	ErrInvalidPlan = errors.New("invalid plan")
+)
+
+// UpstreamError is returned when wger answers with a non-2xx status.
+type UpstreamError struct {
+	StatusCode int
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("wger returned %d", e.StatusCode)
+}
+
+// Unwrap classifies the status: overload and maintenance responses count as unavailable,
+// anything else as a bad response.
+func (e *UpstreamError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return ErrUpstreamUnavailable
+	}
+	return ErrUpstreamBadResponse
+}
+
+// PartialError reports that one leg of a wger query failed while the other succeeded, so the
+// accompanying results are incomplete.
+type PartialError struct {
+	// Leg is the wger filter that failed, e.g. "muscles_secondary".
+	Leg string
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%s query failed: %v", e.Leg, e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
@@ -1,20 +1,262 @@
 package models
 
+import "time"
+
 type Exercise struct {
-	ID               int    `json:"id"`
-	Name             string `json:"name"`
-	Description      string `json:"description"`
-	Category         int    `json:"category"`
-	Muscles          []int  `json:"muscles"`
-	MusclesSecondary []int  `json:"muscles_secondary"`
-	Equipment        []int  `json:"equipment"`
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Category         int      `json:"category"`
+	Muscles          []int    `json:"muscles"`
+	MusclesSecondary []int    `json:"muscles_secondary"`
+	Equipment        []int    `json:"equipment"`
+	CategoryDetail   *Lookup  `json:"category_detail,omitempty"`
+	EquipmentDetail  []Lookup `json:"equipment_detail,omitempty"`
+	// BaseID is the wger exercise base shared by all translations of an exercise.
+	BaseID int `json:"base_id,omitempty"`
+
+	// The fields below are only filled from wger's exerciseinfo endpoint.
+
+	// Language is the code of the language of Name and Description; it differs from the requested
+	// one when the exercise had no translation in it.
+	Language     string        `json:"language,omitempty"`
+	Aliases      []string      `json:"aliases,omitempty"`
+	Translations []Translation `json:"translations,omitempty"`
+	Images       []Media       `json:"images,omitempty"`
+	Videos       []Media       `json:"videos,omitempty"`
+	License      *License      `json:"license,omitempty"`
+	// Author credits the text, or the exercise when the translation names nobody.
+	Author string `json:"author,omitempty"`
+}
+
+// Translation is an exercise's text in one language. Responses only carry Language and Name; the
+// rest is kept so cached exercises can be localized per request.
+type Translation struct {
+	Language    string   `json:"language"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Author      string   `json:"author,omitempty"`
+}
+
+// Media is an exercise image or video hosted by wger.
+type Media struct {
+	URL    string `json:"url"`
+	IsMain bool   `json:"is_main"`
+	Author string `json:"author,omitempty"`
+}
+
+// License is the content license of an exercise.
+type License struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Lookup is a wger reference entity (category, equipment) resolved to its name.
+type Lookup struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Muscle is a wger muscle: Name is the Latin name, NameEn the common English one (may be empty).
+type Muscle struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	NameEn  string `json:"name_en"`
+	IsFront bool   `json:"is_front"`
+}
+
+// MuscleInfo describes a resolvable muscle (or aggregate group such as "back") for clients.
+type MuscleInfo struct {
+	Name           string   `json:"name"`
+	IDs            []int    `json:"ids"`
+	Aliases        []string `json:"aliases,omitempty"`
+	Aggregate      bool     `json:"aggregate"`
+	LatinName      string   `json:"latin_name,omitempty"`
+	EnglishName    string   `json:"english_name,omitempty"`
+	IsFront        *bool    `json:"is_front,omitempty"`
+	SimilarMuscles []string `json:"similar_muscles,omitempty"`
+	ExerciseCount  int      `json:"exercise_count"`
 }
 
 type ExercisesResponse struct {
 	Muscle         string     `json:"muscle"`
 	Exercises      []Exercise `json:"exercises"`
+	Total          int        `json:"total"`
+	Offset         int        `json:"offset"`
+	Limit          int        `json:"limit"`
+	Next           string     `json:"next,omitempty"`
+	Prev           string     `json:"prev,omitempty"`
 	SimilarMuscles []string   `json:"similar_muscles,omitempty"`
 	Advice         string     `json:"advice,omitempty"`
+	// Tips are the ranked rule-engine tips for the returned exercise mix.
+	Tips []Tip `json:"tips,omitempty"`
+	// Language is the code of the language names and descriptions are in; it differs from the
+	// requested one when no translation existed and English was served instead.
+	Language string `json:"language,omitempty"`
+	// Stale is set when the exercises come from an outdated copy because wger is slow or down.
+	Stale bool `json:"stale,omitempty"`
+	// Partial is set when exercises that only work the muscle secondarily could not be fetched;
+	// Warnings says why.
+	Partial  bool     `json:"partial,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	// Age is how old the served data is; it is reported through the Age header, not the body.
+	Age time.Duration `json:"-"`
+}
+
+// Workout is a generated training session.
+type Workout struct {
+	Muscles          []string      `json:"muscles"`
+	Synergists       []string      `json:"synergists,omitempty"`
+	Level            string        `json:"level"`
+	DurationMinutes  int           `json:"duration_minutes"`
+	EstimatedMinutes int           `json:"estimated_minutes"`
+	WarmUp           []WorkoutItem `json:"warm_up"`
+	Compounds        []WorkoutItem `json:"compounds"`
+	Accessories      []WorkoutItem `json:"accessories"`
+	Stale            bool          `json:"stale,omitempty"`
+}
+
+// SplitPlan is a weekly programme; Schedule runs Monday (day 1) to Sunday (day 7).
+type SplitPlan struct {
+	Type     string    `json:"type"`
+	Days     int       `json:"days"`
+	Level    string    `json:"level"`
+	Schedule []PlanDay `json:"schedule"`
+	Stale    bool      `json:"stale,omitempty"`
+}
+
+type PlanDay struct {
+	Day     int      `json:"day"`
+	Weekday string   `json:"weekday"`
+	Name    string   `json:"name"`
+	Rest    bool     `json:"rest,omitempty"`
+	Muscles []string `json:"muscles,omitempty"`
+	Workout *Workout `json:"workout,omitempty"`
+}
+
+// WorkoutItem is one exercise of a session. ExerciseID is empty for generic items such as cardio.
+type WorkoutItem struct {
+	ExerciseID  int    `json:"exercise_id,omitempty"`
+	Name        string `json:"name"`
+	Muscle      string `json:"muscle,omitempty"`
+	Sets        int    `json:"sets"`
+	Reps        string `json:"reps"`
+	RestSeconds int    `json:"rest_seconds"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// User is a registered account.
+type User struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIToken describes an API token. Token holds the secret and is only set when the token is issued.
+type APIToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// SavedWorkout is a workout a user kept, typically one returned by /workouts/generate.
+type SavedWorkout struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Workout   Workout   `json:"workout"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Favorite is a bookmarked exercise; Name is known once the exercise has been mirrored locally.
+type Favorite struct {
+	ExerciseID int       `json:"exercise_id"`
+	Name       string    `json:"name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SetLog is one completed set. Weight is in kilograms (0 for bodyweight), RPE is optional (1-10).
+// Muscles are the exercise's primary muscle IDs, known once the exercise has been mirrored locally.
+type SetLog struct {
+	ID           int64     `json:"id"`
+	ExerciseID   int       `json:"exercise_id"`
+	ExerciseName string    `json:"exercise_name,omitempty"`
+	Muscles      []int     `json:"muscles,omitempty"`
+	Weight       float64   `json:"weight"`
+	Reps         int       `json:"reps"`
+	RPE          *float64  `json:"rpe,omitempty"`
+	PerformedAt  time.Time `json:"performed_at"`
+	E1RM         float64   `json:"e1rm"`
+	// Records lists the personal records this set broke; only set in the response to logging it.
+	Records []string `json:"records,omitempty"`
+}
+
+// PersonalRecord is the best set of an exercise for one kind of record (e1rm, weight or volume).
+type PersonalRecord struct {
+	ExerciseID   int       `json:"exercise_id"`
+	ExerciseName string    `json:"exercise_name,omitempty"`
+	Kind         string    `json:"kind"`
+	Value        float64   `json:"value"`
+	SetID        int64     `json:"set_id"`
+	Weight       float64   `json:"weight"`
+	Reps         int       `json:"reps"`
+	PerformedAt  time.Time `json:"performed_at"`
+}
+
+// ProgressPoint summarises one training day of an exercise.
+type ProgressPoint struct {
+	Date     string  `json:"date"`
+	Sets     int     `json:"sets"`
+	Volume   float64 `json:"volume"`
+	BestE1RM float64 `json:"best_e1rm"`
+}
+
+type ExerciseProgress struct {
+	ExerciseID   int              `json:"exercise_id"`
+	ExerciseName string           `json:"exercise_name,omitempty"`
+	Sets         int              `json:"sets"`
+	Records      []PersonalRecord `json:"records"`
+	History      []ProgressPoint  `json:"history"`
+}
+
+// WeeklyVolume is the work done for a muscle group in the week starting WeekStart (a Monday, UTC).
+type WeeklyVolume struct {
+	WeekStart string  `json:"week_start"`
+	Muscle    string  `json:"muscle"`
+	Sets      int     `json:"sets"`
+	Reps      int     `json:"reps"`
+	Volume    float64 `json:"volume"`
+}
+
+// CircuitStatus reports the wger circuit breaker: closed (normal), open (failing fast until RetryAt)
+// or half_open (letting a probe through).
+type CircuitStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// KeyUsage counts requests made with one API key, or in the "anonymous" (no key), "invalid"
+// (rejected key) and "unverified" (not checked) buckets.
+type KeyUsage struct {
+	Key      string    `json:"key"`
+	Requests uint64    `json:"requests"`
+	Limited  uint64    `json:"limited"`
+	Rejected uint64    `json:"rejected"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Tip is one piece of advice produced by an advice rule, with why it fired.
+type Tip struct {
+	RuleID  string   `json:"rule_id"`
+	Text    string   `json:"text"`
+	Score   int      `json:"score"`
+	Reason  string   `json:"reason,omitempty"`
+	Matched []string `json:"matched,omitempty"`
 }
 
 type AdviceSlip struct {
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// requireAdmin only lets requests carrying `Authorization: Bearer <ADMIN_TOKEN>` through.
+func (h *Handler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.AdminToken == "" {
+			util.WriteProblem(w, r, http.StatusNotFound, "admin endpoints are disabled")
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.AdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			util.WriteProblem(w, r, http.StatusUnauthorized, "missing or invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GET /admin/cache
+func (h *Handler) cacheStats(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSON(w, http.StatusOK, h.svc.CacheStats())
+}
+
+// DELETE /admin/cache[?muscle=chest]
+func (h *Handler) purgeCache(w http.ResponseWriter, r *http.Request) {
+	muscle := r.URL.Query().Get("muscle")
+	n, err := h.svc.PurgeCache(r.Context(), muscle)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.logger.PrintInfo("cache purged", map[string]string{"muscle": muscle, "removed": strconv.Itoa(n)})
+	util.WriteJSON(w, http.StatusOK, map[string]any{"removed": n})
+}
+
+// GET /admin/keys
+func (h *Handler) keyUsage(w http.ResponseWriter, r *http.Request) {
+	if h.cfg.APIKeys == nil {
+		util.WriteProblem(w, r, http.StatusNotFound, "API keys are not enabled")
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"keys": h.cfg.APIKeys.Usage()})
+}
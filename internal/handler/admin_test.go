@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminRoutes_RequireToken(t *testing.T) {
+	h := newWgerHandler(t)
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/cache", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.Router().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// /admin skips API keys, so without ADMIN_TOKEN nothing may get through, whatever is sent
+	for _, token := range []string{"", "anything"} {
+		if code := get(token); code != http.StatusNotFound {
+			t.Fatalf("admin disabled, token %q: status %d, want 404", token, code)
+		}
+	}
+
+	h.cfg.AdminToken = "secret"
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "secret": http.StatusOK} {
+		if code := get(token); code != want {
+			t.Fatalf("token %q: status %d, want %d", token, code, want)
+		}
+	}
+}
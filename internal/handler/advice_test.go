@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetAdvice_FallsBackThroughChain(t *testing.T) {
+	var down atomic.Bool
+	slip := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, `{"slip":{"id":1,"advice":"Smile more."}}`)
+	}))
+	defer slip.Close()
+
+	tips := filepath.Join(t.TempDir(), "tips.json")
+	if err := os.WriteFile(tips, []byte(`{"general":["Squat deep."],"muscles":{"Chest":["Retract your shoulder blades.","Touch your chest."]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := service.NewFileProvider(tips)
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+
+	chain := service.ChainProvider{service.NewSlipProvider(slip.Client(), slip.URL), file}
+	h := newWgerHandler(t)
+	h.cfg.Advice = service.NewCachedProvider(chain, time.Hour)
+
+	get := func(target string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d", rec.Code)
+		}
+		var dto adviceDTO
+		if err := json.NewDecoder(rec.Body).Decode(&dto); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return dto.Advice
+	}
+
+	if got := get("/advice"); got != "Smile more." {
+		t.Fatalf("expected slip advice, got %q", got)
+	}
+	// aliases resolve through the muscle registry, and the cached tips still vary per request
+	seen := map[string]bool{}
+	for range 50 {
+		seen[get("/advice?muscle=Pecs")] = true
+	}
+	if len(seen) != 2 || !seen["Retract your shoulder blades."] || !seen["Touch your chest."] {
+		t.Fatalf("expected both curated chest tips, got %v", seen)
+	}
+	for _, muscle := range []string{"neck", "wings"} {
+		if got := get("/advice?muscle=" + muscle); got != "Smile more." {
+			t.Fatalf("expected general advice for %s, got %q", muscle, got)
+		}
+	}
+	down.Store(true)
+	h.cfg.Advice = chain
+	if got := get("/advice"); got != "Squat deep." {
+		t.Fatalf("expected curated fallback, got %q", got)
+	}
+
+	h.cfg.Advice = service.ChainProvider{service.NewSlipProvider(slip.Client(), slip.URL)}
+	if got := get("/advice"); got != service.NoAdvice {
+		t.Fatalf("expected %q when every provider fails, got %q", service.NoAdvice, got)
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+	"net/http"
+)
+
+// statusClientClosedRequest is nginx's non-standard status for requests the client abandoned.
+const statusClientClosedRequest = 499
+
+// upstreamDetails replace the wrapped transport errors, which name wger URLs, in responses.
+var upstreamDetails = map[int]string{
+	http.StatusBadGateway:         "wger returned an invalid response",
+	http.StatusServiceUnavailable: "wger is unavailable, try again later",
+	http.StatusGatewayTimeout:     "wger did not respond in time",
+}
+
+// statusFor maps domain errors onto HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	case errors.Is(err, models.ErrUnknownMuscle), errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrEmailTaken):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, models.ErrInvalidIDs), errors.Is(err, models.ErrInvalidPlan):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrUpstreamTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, models.ErrUpstreamUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, models.ErrUpstreamBadResponse):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError renders err as a problem+json body. Internal errors are logged and not echoed to clients.
+// Clients that went away get a bare 499 and no log entry.
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusFor(err)
+	if status == statusClientClosedRequest {
+		w.WriteHeader(status)
+		return
+	}
+	detail := err.Error()
+	if d, ok := upstreamDetails[status]; ok {
+		detail = d
+	} else if status == http.StatusInternalServerError {
+		detail = "internal error"
+	}
+	if status >= http.StatusInternalServerError {
+		h.logger.PrintError("request failed", map[string]string{
+			"path":   r.URL.Path,
+			"status": http.StatusText(status),
+			"error":  err.Error(),
+		})
+	}
+	util.WriteProblem(w, r, status, detail)
+}
+
+// badRequest rejects invalid query or body input.
+func badRequest(w http.ResponseWriter, r *http.Request, err error) {
+	util.WriteProblem(w, r, http.StatusBadRequest, err.Error())
+}
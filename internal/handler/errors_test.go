@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStatusFor(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w \"wings\"", models.ErrUnknownMuscle), http.StatusNotFound},
+		{fmt.Errorf("%w: -4", models.ErrInvalidIDs), http.StatusUnprocessableEntity},
+		{fmt.Errorf("%w: ppl needs 3 to 6 days", models.ErrInvalidPlan), http.StatusUnprocessableEntity},
+		{&models.UpstreamError{StatusCode: http.StatusServiceUnavailable}, http.StatusServiceUnavailable},
+		{&models.UpstreamError{StatusCode: http.StatusTooManyRequests}, http.StatusServiceUnavailable},
+		{&models.UpstreamError{StatusCode: http.StatusInternalServerError}, http.StatusBadGateway},
+		{fmt.Errorf("%w: unexpected EOF", models.ErrUpstreamBadResponse), http.StatusBadGateway},
+		{fmt.Errorf("%w: dial tcp: connection refused", models.ErrUpstreamUnavailable), http.StatusServiceUnavailable},
+		{fmt.Errorf("%w: i/o timeout", models.ErrUpstreamTimeout), http.StatusGatewayTimeout},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{fmt.Errorf("%w: %w", models.ErrUpstreamUnavailable, context.Canceled), statusClientClosedRequest},
+		{models.ErrNotFound, http.StatusNotFound},
+		{models.ErrEmailTaken, http.StatusConflict},
+		{models.ErrInvalidCredentials, http.StatusUnauthorized},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := statusFor(tt.err); got != tt.want {
+			t.Errorf("statusFor(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWriteError_HidesUpstreamDetails(t *testing.T) {
+	var logged bytes.Buffer
+	h := New(nil, jsonlog.New(&logged, jsonlog.LevelInfo), Config{})
+	write := func(err error) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.writeError(rec, httptest.NewRequest(http.MethodGet, "/exercises/chest", nil), err)
+		return rec
+	}
+
+	rec := write(fmt.Errorf("%w: Get \"https://wger.de/api/v2/exercise/?muscles=4\": i/o timeout", models.ErrUpstreamTimeout))
+	if rec.Code != http.StatusGatewayTimeout || strings.Contains(rec.Body.String(), "wger.de") {
+		t.Fatalf("upstream error leaked: %d %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(logged.String(), "wger.de") {
+		t.Fatal("the full error should still be logged")
+	}
+
+	logged.Reset()
+	if rec := write(context.Canceled); rec.Code != statusClientClosedRequest || logged.Len() != 0 {
+		t.Fatalf("canceled request: status %d, log %q", rec.Code, logged.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
 	"github.com/m4rk1sov/rbk-api/internal/repository"
 	"github.com/m4rk1sov/rbk-api/internal/service"
 	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
@@ -16,36 +17,63 @@ import (
 
 type adviceDTO struct {
 	Advice string `json:"advice"`
+	Muscle string `json:"muscle,omitempty"`
 }
 
 type musclesDTO struct {
 	Muscles []string `json:"muscles"`
 }
 
+type muscleCatalogDTO struct {
+	Muscles []models.MuscleInfo `json:"muscles"`
+}
+
+// Config holds optional handler settings.
+type Config struct {
+	// AdminToken guards the /admin routes; they are disabled when it is empty.
+	AdminToken string
+	// Advice answers GET /advice; defaults to adviceslip.com.
+	Advice service.AdviceProvider
+	// Users backs the account routes; they are disabled when it is nil.
+	Users *service.UserService
+	// APIKeys, when set, authenticates API keys and rate limits every route.
+	APIKeys *repository.APIKeys
+	// Upstream, when set, reports the wger circuit breaker on /healthz.
+	Upstream *repository.Transport
+}
+
 type Handler struct {
 	r       *chi.Mux
 	svc     *service.FitnessService
 	logger  *jsonlog.Logger
+	cfg     Config
 	started time.Time
 }
 
-func New(svc *service.FitnessService, logger *jsonlog.Logger) *Handler {
+func New(svc *service.FitnessService, logger *jsonlog.Logger, cfg Config) *Handler {
 	h := &Handler{
 		r:       chi.NewRouter(),
 		svc:     svc,
 		logger:  logger,
+		cfg:     cfg,
 		started: time.Now(),
 	}
+	if h.cfg.Advice == nil {
+		h.cfg.Advice = service.NewSlipProvider(nil, "")
+	}
 
 	// Middlewares
 	h.r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 	h.r.Use(repository.RequestLogger(logger))
+	if h.cfg.APIKeys != nil {
+		h.r.Use(h.cfg.APIKeys.Middleware)
+	}
 
 	// Routes
 	// swagger routes
@@ -60,6 +88,50 @@ func New(svc *service.FitnessService, logger *jsonlog.Logger) *Handler {
 	h.r.Get("/exercises", h.listMuscles)
 	h.r.Get("/exercises/", h.listMuscles)
 
+	// Muscle resources with IDs, aliases and related groups
+	h.r.Get("/muscles", h.getMuscles)
+	h.r.Get("/muscles/{name}", h.getMuscle)
+
+	// Workout generator and weekly plans
+	h.r.Post("/workouts/generate", h.generateWorkout)
+	h.r.Get("/plans/split", h.getSplitPlan)
+
+	// Accounts, saved workouts, favourites and training logs
+	h.r.Route("/users", func(r chi.Router) {
+		r.Use(h.requireAccounts)
+		r.Post("/register", h.register)
+		r.Post("/login", h.login)
+	})
+	h.r.Route("/me", func(r chi.Router) {
+		r.Use(h.requireAccounts, h.requireUser)
+		r.Get("/", h.me)
+		r.Get("/tokens", h.listTokens)
+		r.Post("/tokens", h.createToken)
+		r.Delete("/tokens/{id}", h.revokeToken)
+		r.Get("/workouts", h.listWorkouts)
+		r.Post("/workouts", h.saveWorkout)
+		r.Get("/workouts/{id}", h.getWorkout)
+		r.Put("/workouts/{id}", h.updateWorkout)
+		r.Delete("/workouts/{id}", h.deleteWorkout)
+		r.Get("/favorites", h.listFavorites)
+		r.Put("/favorites/{exerciseID}", h.addFavorite)
+		r.Delete("/favorites/{exerciseID}", h.removeFavorite)
+		r.Get("/sets", h.listSets)
+		r.Post("/sets", h.logSet)
+		r.Delete("/sets/{id}", h.deleteSet)
+		r.Get("/progress/{exerciseID}", h.exerciseProgress)
+		r.Get("/records", h.personalRecords)
+		r.Get("/volume", h.weeklyVolume)
+	})
+
+	// Admin
+	h.r.Route("/admin", func(r chi.Router) {
+		r.Use(h.requireAdmin)
+		r.Get("/cache", h.cacheStats)
+		r.Delete("/cache", h.purgeCache)
+		r.Get("/keys", h.keyUsage)
+	})
+
 	// Redirect all unknown routes to /exercises
 	h.r.NotFound(h.redirectToExercises)
 
@@ -71,30 +143,72 @@ func (h *Handler) Router() http.Handler {
 	return h.r
 }
 
+// health stays 200 while wger is unreachable (the offline mirror still answers) but reports "degraded".
 func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
-	util.WriteJSON(w, http.StatusOK, map[string]any{
+	body := map[string]any{
 		"status":  "ok",
 		"uptime":  time.Since(h.started).String(),
 		"service": "rbk-api",
-	})
+	}
+	if h.cfg.Upstream != nil {
+		circuit := h.cfg.Upstream.Circuit()
+		if circuit.State != "closed" {
+			body["status"] = "degraded"
+		}
+		body["wger"] = circuit
+	}
+	util.WriteJSON(w, http.StatusOK, body)
 }
 
 func (h *Handler) getExercises(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	muscle := chi.URLParam(r, "muscle")
-	limit := 20
-	if s := r.URL.Query().Get("limit"); s != "" {
-		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 100 {
-			limit = n
-		}
+	limit, offset, err := parsePage(r.URL.Query())
+	if err != nil {
+		badRequest(w, r, err)
+		return
 	}
 
-	resp, err := h.svc.GetExercisesByMuscle(ctx, muscle, limit)
+	expandCategory, expandEquipment, err := parseExpand(r.URL.Query().Get("expand"))
 	if err != nil {
-		h.logger.PrintError("failed to get exercises", map[string]string{"muscle": muscle})
-		util.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		badRequest(w, r, err)
 		return
 	}
+
+	query := service.ExerciseQuery{
+		Limit:           limit,
+		Offset:          offset,
+		ExpandCategory:  expandCategory,
+		ExpandEquipment: expandEquipment,
+	}
+	if err := parseExerciseFilters(r.URL.Query(), &query); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	if query.Language, err = parseLanguage(r); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+
+	resp, err := h.svc.GetExercisesByMuscle(ctx, muscle, query)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if next := resp.Offset + resp.Limit; next < resp.Total {
+		resp.Next = pageLink(r.URL, next)
+	}
+	if resp.Offset > 0 {
+		resp.Prev = pageLink(r.URL, max(resp.Offset-resp.Limit, 0))
+	}
+	if resp.Age > 0 {
+		w.Header().Set("Age", strconv.Itoa(int(resp.Age.Seconds())))
+	}
+	if resp.Stale {
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+	}
+	w.Header().Set("Content-Language", resp.Language)
+	w.Header().Add("Vary", "Accept-Language")
 	util.WriteJSON(w, http.StatusOK, resp)
 }
 
@@ -102,11 +216,27 @@ func (h *Handler) getExercises(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) listMuscles(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	muscles := service.GetAvailableMuscles()
+	muscles := h.svc.Muscles().Names()
 
 	_ = json.NewEncoder(w).Encode(musclesDTO{Muscles: muscles})
 }
 
+// GET /muscles
+func (h *Handler) getMuscles(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSON(w, http.StatusOK, muscleCatalogDTO{Muscles: h.svc.MuscleCatalog(r.Context())})
+}
+
+// GET /muscles/{name}
+func (h *Handler) getMuscle(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	info, ok := h.svc.MuscleInfo(r.Context(), name)
+	if !ok {
+		util.WriteProblem(w, r, http.StatusNotFound, "unknown muscle: "+name)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, info)
+}
+
 // NotFound -> redirect to /exercises
 func (h *Handler) redirectToExercises(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet || r.Method == http.MethodHead {
@@ -116,7 +246,22 @@ func (h *Handler) redirectToExercises(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/exercises", http.StatusTemporaryRedirect)
 }
 
+// GET /advice[?muscle=chest]; the muscle may be any name or alias. Unknown muscles and muscles
+// without curated tips get general advice.
 func (h *Handler) getAdvice(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	_ = json.NewEncoder(w).Encode(adviceDTO{Advice: service.GetAdvice()})
+	ctx := r.Context()
+	if g, ok := h.svc.Muscles().Group(r.URL.Query().Get("muscle")); ok {
+		for _, name := range append([]string{g.Name}, g.Aliases...) {
+			if advice, err := h.cfg.Advice.Advice(ctx, name); err == nil {
+				util.WriteJSON(w, http.StatusOK, adviceDTO{Advice: advice, Muscle: g.Name})
+				return
+			}
+		}
+	}
+	advice, err := h.cfg.Advice.Advice(ctx, "")
+	if err != nil {
+		h.logger.PrintError("failed to get advice", map[string]string{"error": err.Error()})
+		advice = service.NoAdvice
+	}
+	util.WriteJSON(w, http.StatusOK, adviceDTO{Advice: advice})
 }
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// cursor is the opaque position handed out in next/prev links.
+type cursor struct {
+	Offset int `json:"o"`
+}
+
+func encodeCursor(offset int) string {
+	b, _ := json.Marshal(cursor{Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Offset < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return c.Offset, nil
+}
+
+// parsePage reads limit, offset and cursor from the query; a cursor takes precedence over offset.
+func parsePage(q url.Values) (limit, offset int, err error) {
+	limit = 20
+	if s := q.Get("limit"); s != "" {
+		n, convErr := strconv.Atoi(s)
+		if convErr != nil || n < 1 || n > 100 {
+			return 0, 0, errors.New("limit must be between 1 and 100")
+		}
+		limit = n
+	}
+	if s := q.Get("offset"); s != "" {
+		n, convErr := strconv.Atoi(s)
+		if convErr != nil || n < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+	if s := q.Get("cursor"); s != "" {
+		if offset, err = decodeCursor(s); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
+
+// pageLink rebuilds the request URL pointing at offset, keeping every other query parameter.
+func pageLink(u *url.URL, offset int) string {
+	q := u.Query()
+	q.Del("offset")
+	q.Set("cursor", encodeCursor(offset))
+	return u.Path + "?" + q.Encode()
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query         string
+		limit, offset int
+		wantErr       bool
+	}{
+		{"", 20, 0, false},
+		{"limit=5&offset=10", 5, 10, false},
+		{"limit=100", 100, 0, false},
+		{"offset=10&cursor=" + encodeCursor(40), 20, 40, false},
+		{"limit=0", 0, 0, true},
+		{"limit=101", 0, 0, true},
+		{"limit=ten", 0, 0, true},
+		{"offset=-1", 0, 0, true},
+		{"cursor=***", 0, 0, true},
+		{"cursor=bm90LWpzb24", 0, 0, true}, // "not-json"
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		limit, offset, err := parsePage(q)
+		if (err != nil) != tt.wantErr || limit != tt.limit || offset != tt.offset {
+			t.Errorf("parsePage(%q) = %d, %d, %v", tt.query, limit, offset, err)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 1, 40, 1 << 20} {
+		got, err := decodeCursor(encodeCursor(offset))
+		if err != nil || got != offset {
+			t.Errorf("decodeCursor(encodeCursor(%d)) = %d, %v", offset, got, err)
+		}
+	}
+	if _, err := decodeCursor(encodeCursor(-1)); err == nil {
+		t.Error("a negative offset must be rejected")
+	}
+}
+
+func TestPageLink(t *testing.T) {
+	u, _ := url.Parse("/exercises/chest?limit=10&offset=30&sort=name&cursor=old")
+	got := pageLink(u, 40)
+	want := "/exercises/chest?cursor=" + encodeCursor(40) + "&limit=10&sort=name"
+	if got != want {
+		t.Fatalf("pageLink = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// parseExpand reads `expand=category,equipment` (or all/none/true/false).
+func parseExpand(s string) (category, equipment bool, err error) {
+	for _, part := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "", "none", "false":
+		case "all", "true":
+			category, equipment = true, true
+		case "category":
+			category = true
+		case "equipment":
+			equipment = true
+		default:
+			return false, false, errors.New("expand must be a list of: category, equipment (or all, none)")
+		}
+	}
+	return category, equipment, nil
+}
+
+// parseIDList reads a comma-separated list of positive integer IDs; an empty value yields nil.
+func parseIDList(name, s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	out := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n <= 0 {
+			return nil, errors.New(name + " must be a comma-separated list of positive IDs")
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// parseExerciseFilters reads the equipment, category, exclude_equipment and role filters, the sort
+// order and the description format.
+func parseExerciseFilters(q url.Values, dst *service.ExerciseQuery) error {
+	var err error
+	if dst.Equipment, err = parseIDList("equipment", q.Get("equipment")); err != nil {
+		return err
+	}
+	if dst.Category, err = parseIDList("category", q.Get("category")); err != nil {
+		return err
+	}
+	if dst.ExcludeEquipment, err = parseIDList("exclude_equipment", q.Get("exclude_equipment")); err != nil {
+		return err
+	}
+	role, ok := service.ParseRole(strings.ToLower(q.Get("role")))
+	if !ok {
+		return errors.New("role must be one of: primary, secondary, any")
+	}
+	dst.Role = role
+	order, ok := service.ParseSort(strings.ToLower(q.Get("sort")))
+	if !ok {
+		return errors.New("sort must be one of: id, name, relevance")
+	}
+	dst.Sort = order
+	format, ok := service.ParseDescriptionFormat(strings.ToLower(q.Get("format")))
+	if !ok {
+		return errors.New("format must be one of: html, text, markdown")
+	}
+	dst.Format = format
+	return nil
+}
+
+// parseLanguage reads `lang=de`, falling back to the Accept-Language header. An unsupported lang
+// parameter is an error; an unsupported header just leaves the server default.
+func parseLanguage(r *http.Request) (string, error) {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		if _, ok := repository.LanguageID(lang); !ok {
+			return "", errors.New("lang must be a language wger supports, e.g. en, de, fr")
+		}
+		return lang, nil
+	}
+	return service.NegotiateLanguage(r.Header.Get("Accept-Language")), nil
+}
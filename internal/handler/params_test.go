@@ -0,0 +1,26 @@
+package handler
+
+import "testing"
+
+func TestParseExpand(t *testing.T) {
+	tests := []struct {
+		in                  string
+		category, equipment bool
+		wantErr             bool
+	}{
+		{"", false, false, false},
+		{"none", false, false, false},
+		{"category", true, false, false},
+		{"Equipment", false, true, false},
+		{"category, equipment", true, true, false},
+		{"all", true, true, false},
+		{"true", true, true, false},
+		{"category,muscles", false, false, true},
+	}
+	for _, tt := range tests {
+		category, equipment, err := parseExpand(tt.in)
+		if (err != nil) != tt.wantErr || category != tt.category || equipment != tt.equipment {
+			t.Errorf("parseExpand(%q) = %v, %v, %v", tt.in, category, equipment, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GET /plans/split?type=ppl&days=6[&level=beginner&duration=60&equipment=1,3]
+func (h *Handler) getSplitPlan(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	splitType, ok := service.ParseSplitType(strings.ToLower(strings.TrimSpace(q.Get("type"))))
+	if !ok {
+		badRequest(w, r, errors.New("type must be one of: ppl, upper_lower, full_body"))
+		return
+	}
+	days, lo, hi := service.SplitDays(splitType)
+	if s := q.Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < lo || n > hi {
+			badRequest(w, r, fmt.Errorf("days must be between %d and %d for %s", lo, hi, splitType))
+			return
+		}
+		days = n
+	}
+	minutes := 60
+	if s := q.Get("duration"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < minWorkoutMinutes || n > maxWorkoutMinutes {
+			badRequest(w, r, fmt.Errorf("duration must be between %d and %d minutes", minWorkoutMinutes, maxWorkoutMinutes))
+			return
+		}
+		minutes = n
+	}
+	equipment, err := parseIDList("equipment", q.Get("equipment"))
+	if err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	level, ok := service.ParseLevel(strings.ToLower(strings.TrimSpace(q.Get("level"))))
+	if !ok {
+		badRequest(w, r, errors.New("level must be one of: beginner, intermediate, advanced"))
+		return
+	}
+
+	plan, err := h.svc.PlanSplit(r.Context(), service.PlanRequest{
+		Type:      splitType,
+		Days:      days,
+		Minutes:   minutes,
+		Equipment: equipment,
+		Level:     level,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if plan.Stale {
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+	}
+	util.WriteJSON(w, http.StatusOK, plan)
+}
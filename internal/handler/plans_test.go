@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newWgerHandler serves the exercise routes from a fake wger where muscle m has an isolation
+// exercise m*10+1 and a press m*10+2 that also works the triceps (5).
+func newWgerHandler(t *testing.T) *Handler {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m, err := strconv.Atoi(r.URL.Query().Get("muscles"))
+		if err != nil {
+			_, _ = io.WriteString(w, `{"count":0,"results":[]}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"count":2,"results":[
+			{"id":%d,"name":"Isolation %d","category":10,"muscles":[%d]},
+			{"id":%d,"name":"Press %d","category":11,"muscles":[%d,5]}]}`, m*10+1, m, m, m*10+2, m, m)
+	}))
+	t.Cleanup(srv.Close)
+	logger := jsonlog.New(io.Discard, jsonlog.LevelOff)
+	client := repository.NewWgerClient(srv.Client(), srv.URL, 2, "test")
+	return New(service.NewFitnessService(client, nil, logger, ""), logger, Config{})
+}
+
+// serve runs a request through the router, failing the test on an unexpected status.
+func serve(t *testing.T, h *Handler, method, target, body string, wantStatus int, dst any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Router().ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if rec.Code != wantStatus {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, target, rec.Code, wantStatus, rec.Body)
+	}
+	if dst != nil {
+		if err := json.NewDecoder(rec.Body).Decode(dst); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, target, err)
+		}
+	}
+}
+
+func TestSplitPlanRoute(t *testing.T) {
+	h := newWgerHandler(t)
+	for _, q := range []string{
+		"type=bro",
+		"type=ppl&days=7",
+		"type=upper_lower&days=x",
+		"type=ppl&duration=5",
+		"type=ppl&level=expert",
+		"type=ppl&equipment=barbell",
+	} {
+		serve(t, h, http.MethodGet, "/plans/split?"+q, "", http.StatusBadRequest, nil)
+	}
+
+	var plan models.SplitPlan
+	serve(t, h, http.MethodGet, "/plans/split?type=ppl&days=3&level=beginner", "", http.StatusOK, &plan)
+	training := 0
+	for _, d := range plan.Schedule {
+		if !d.Rest {
+			training++
+		}
+	}
+	if plan.Type != "ppl" || len(plan.Schedule) != 7 || training != 3 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+}
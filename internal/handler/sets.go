@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxSetWeight    = 1000
+	maxSetReps      = 1000
+	maxHistoryLimit = 500
+	maxVolumeWeeks  = 52
+)
+
+type logSetDTO struct {
+	ExerciseID  int        `json:"exercise_id"`
+	Weight      float64    `json:"weight"`
+	Reps        int        `json:"reps"`
+	RPE         *float64   `json:"rpe"`
+	PerformedAt *time.Time `json:"performed_at"`
+}
+
+func (in logSetDTO) toSet() (models.SetLog, error) {
+	if in.ExerciseID <= 0 {
+		return models.SetLog{}, errors.New("exercise_id must be a positive wger exercise ID")
+	}
+	if in.Weight < 0 || in.Weight > maxSetWeight {
+		return models.SetLog{}, fmt.Errorf("weight must be between 0 and %d kg", maxSetWeight)
+	}
+	if in.Reps < 1 || in.Reps > maxSetReps {
+		return models.SetLog{}, fmt.Errorf("reps must be between 1 and %d", maxSetReps)
+	}
+	if in.RPE != nil && (*in.RPE < 1 || *in.RPE > 10) {
+		return models.SetLog{}, errors.New("rpe must be between 1 and 10")
+	}
+	set := models.SetLog{ExerciseID: in.ExerciseID, Weight: in.Weight, Reps: in.Reps, RPE: in.RPE, PerformedAt: time.Now().UTC()}
+	if in.PerformedAt != nil {
+		if in.PerformedAt.After(time.Now().Add(time.Hour)) {
+			return models.SetLog{}, errors.New("performed_at must not be in the future")
+		}
+		set.PerformedAt = in.PerformedAt.UTC()
+	}
+	return set, nil
+}
+
+// POST /me/sets
+func (h *Handler) logSet(w http.ResponseWriter, r *http.Request) {
+	var in logSetDTO
+	if err := decodeJSON(w, r, &in); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	set, err := in.toSet()
+	if err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	set, err = h.cfg.Users.LogSet(r.Context(), userFrom(r.Context()).ID, set)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusCreated, set)
+}
+
+// GET /me/sets[?exercise=192&muscle=chest&from=2026-01-01&to=2026-02-01&limit=100]
+func (h *Handler) listSets(w http.ResponseWriter, r *http.Request) {
+	q, err := parseSetQuery(r.URL.Query())
+	if err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	sets, err := h.cfg.Users.Sets(r.Context(), userFrom(r.Context()).ID, q)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"sets": sets})
+}
+
+// DELETE /me/sets/{id}
+func (h *Handler) deleteSet(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := h.cfg.Users.DeleteSet(r.Context(), userFrom(r.Context()).ID, id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /me/progress/{exerciseID}
+func (h *Handler) exerciseProgress(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "exerciseID")
+	if !ok {
+		return
+	}
+	p, err := h.cfg.Users.ExerciseProgress(r.Context(), userFrom(r.Context()).ID, int(id))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, p)
+}
+
+// GET /me/records
+func (h *Handler) personalRecords(w http.ResponseWriter, r *http.Request) {
+	records, err := h.cfg.Users.Records(r.Context(), userFrom(r.Context()).ID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"records": records})
+}
+
+// GET /me/volume[?weeks=8&muscle=chest]
+func (h *Handler) weeklyVolume(w http.ResponseWriter, r *http.Request) {
+	weeks := 8
+	if s := r.URL.Query().Get("weeks"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxVolumeWeeks {
+			badRequest(w, r, fmt.Errorf("weeks must be between 1 and %d", maxVolumeWeeks))
+			return
+		}
+		weeks = n
+	}
+	muscle := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("muscle")))
+	volume, err := h.cfg.Users.WeeklyVolume(r.Context(), userFrom(r.Context()).ID, weeks, muscle)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"weeks": weeks, "volume": volume})
+}
+
+// parseSetQuery reads exercise, muscle, from/to (RFC 3339 or YYYY-MM-DD) and limit.
+func parseSetQuery(q url.Values) (service.SetQuery, error) {
+	var out service.SetQuery
+	if s := q.Get("exercise"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return out, errors.New("exercise must be a positive wger exercise ID")
+		}
+		out.ExerciseID = n
+	}
+	out.Muscle = strings.ToLower(strings.TrimSpace(q.Get("muscle")))
+	var err error
+	if out.From, err = parseTime("from", q.Get("from"), false); err != nil {
+		return out, err
+	}
+	if out.To, err = parseTime("to", q.Get("to"), true); err != nil {
+		return out, err
+	}
+	out.Limit = 100
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			return out, fmt.Errorf("limit must be between 1 and %d", maxHistoryLimit)
+		}
+		out.Limit = n
+	}
+	return out, nil
+}
+
+// parseTime reads a timestamp or a date. A date given as the (exclusive) upper bound means the
+// whole day, so the next midnight is returned.
+func parseTime(name, s string, upper bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		if upper {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Time{}, errors.New(name + " must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetRoutes(t *testing.T) {
+	ctx := context.Background()
+	db, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "sets.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	exercises, err := repository.NewExerciseStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exercises.UpsertExercises(ctx, []models.Exercise{{ID: 192, Name: "Bench Press", Muscles: []int{4}}}); err != nil {
+		t.Fatal(err)
+	}
+	store, err := repository.NewUserStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := jsonlog.New(io.Discard, jsonlog.LevelOff)
+	users := service.NewUserService(store).WithMuscles(service.NewMuscleRegistry(nil, logger))
+	h := New(nil, logger, Config{Users: users})
+
+	var token string
+	do := func(method, target, body string, wantStatus int, dst any) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.Router().ServeHTTP(rec, req)
+		if rec.Code != wantStatus {
+			t.Fatalf("%s %s: status %d, want %d: %s", method, target, rec.Code, wantStatus, rec.Body)
+		}
+		if dst != nil {
+			if err := json.NewDecoder(rec.Body).Decode(dst); err != nil {
+				t.Fatalf("%s %s: decode: %v", method, target, err)
+			}
+		}
+	}
+	creds := `{"email":"lifter@example.com","password":"correct horse"}`
+	do(http.MethodPost, "/users/register", creds, http.StatusCreated, nil)
+	var tok models.APIToken
+	do(http.MethodPost, "/users/login", creds, http.StatusCreated, &tok)
+	token = tok.Token
+
+	future := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+	for _, body := range []string{
+		`{"exercise_id":0,"weight":80,"reps":5}`,
+		`{"exercise_id":192,"weight":-1,"reps":5}`,
+		`{"exercise_id":192,"weight":1001,"reps":5}`,
+		`{"exercise_id":192,"weight":80,"reps":0}`,
+		`{"exercise_id":192,"weight":80,"reps":5,"rpe":11}`,
+		`{"exercise_id":192,"weight":80,"reps":5,"performed_at":"` + future + `"}`,
+	} {
+		do(http.MethodPost, "/me/sets", body, http.StatusBadRequest, nil)
+	}
+	do(http.MethodPost, "/me/sets", `{"exercise_id":192,"weight":80,"reps":5,"performed_at":"2026-02-01T18:00:00Z"}`, http.StatusCreated, nil)
+	var set models.SetLog
+	do(http.MethodPost, "/me/sets", `{"exercise_id":192,"weight":100,"reps":5,"rpe":9}`, http.StatusCreated, &set)
+	if set.ID == 0 || set.E1RM == 0 {
+		t.Fatalf("logged set: %+v", set)
+	}
+
+	for _, q := range []string{"limit=0", "limit=501", "from=yesterday", "exercise=-1"} {
+		do(http.MethodGet, "/me/sets?"+q, "", http.StatusBadRequest, nil)
+	}
+	var history struct{ Sets []models.SetLog }
+	// a date-only `to` includes that day, a timestamp is exclusive
+	do(http.MethodGet, "/me/sets?to=2026-02-01", "", http.StatusOK, &history)
+	if len(history.Sets) != 1 || history.Sets[0].Weight != 80 {
+		t.Fatalf("sets up to 2026-02-01: %+v", history.Sets)
+	}
+	do(http.MethodGet, "/me/sets?to=2026-02-01T00:00:00Z", "", http.StatusOK, &history)
+	if len(history.Sets) != 0 {
+		t.Fatalf("sets before 2026-02-01: %+v", history.Sets)
+	}
+
+	for _, q := range []string{"weeks=0", "weeks=53", "weeks=x"} {
+		do(http.MethodGet, "/me/volume?"+q, "", http.StatusBadRequest, nil)
+	}
+	var volume struct {
+		Weeks  int
+		Volume []models.WeeklyVolume
+	}
+	do(http.MethodGet, "/me/volume?weeks=1&muscle=chest", "", http.StatusOK, &volume)
+	if volume.Weeks != 1 || len(volume.Volume) != 1 || volume.Volume[0].Sets != 1 || volume.Volume[0].Volume != 500 {
+		t.Fatalf("volume: %+v", volume)
+	}
+}
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+const (
+	minPasswordLength = 8
+	maxNameLength     = 100
+)
+
+type userCtxKey struct{}
+
+// userFrom returns the user authenticated by requireUser.
+func userFrom(ctx context.Context) models.User {
+	u, _ := ctx.Value(userCtxKey{}).(models.User)
+	return u
+}
+
+// requireAccounts hides the account routes when no user store is configured.
+func (h *Handler) requireAccounts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.Users == nil {
+			util.WriteProblem(w, r, http.StatusNotFound, "accounts are disabled")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireUser authenticates `Authorization: Bearer <api token>`.
+func (h *Handler) requireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="users"`)
+			util.WriteProblem(w, r, http.StatusUnauthorized, "missing API token")
+			return
+		}
+		u, err := h.cfg.Users.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidCredentials) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="users"`)
+			}
+			h.writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userCtxKey{}, u)))
+	})
+}
+
+type credentialsDTO struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	TokenName string `json:"token_name,omitempty"`
+}
+
+// POST /users/register
+func (h *Handler) register(w http.ResponseWriter, r *http.Request) {
+	var in credentialsDTO
+	if err := decodeJSON(w, r, &in); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	if _, err := mail.ParseAddress(in.Email); err != nil || strings.ContainsAny(in.Email, "<> ") {
+		badRequest(w, r, errors.New("email must be a valid address"))
+		return
+	}
+	if len(in.Password) < minPasswordLength {
+		badRequest(w, r, errors.New("password must be at least 8 characters"))
+		return
+	}
+	u, err := h.cfg.Users.Register(r.Context(), in.Email, in.Password)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.logger.PrintInfo("user registered", map[string]string{"user_id": strconv.FormatInt(u.ID, 10)})
+	util.WriteJSON(w, http.StatusCreated, u)
+}
+
+// POST /users/login issues a new API token.
+func (h *Handler) login(w http.ResponseWriter, r *http.Request) {
+	var in credentialsDTO
+	if err := decodeJSON(w, r, &in); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	name, err := tokenName(in.TokenName)
+	if err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	t, err := h.cfg.Users.Login(r.Context(), in.Email, in.Password, name)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusCreated, t)
+}
+
+// GET /me
+func (h *Handler) me(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSON(w, http.StatusOK, userFrom(r.Context()))
+}
+
+// GET /me/tokens
+func (h *Handler) listTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.cfg.Users.Tokens(r.Context(), userFrom(r.Context()).ID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"tokens": tokens})
+}
+
+// POST /me/tokens
+func (h *Handler) createToken(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(w, r, &in); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	name, err := tokenName(in.Name)
+	if err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	t, err := h.cfg.Users.IssueToken(r.Context(), userFrom(r.Context()).ID, name)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusCreated, t)
+}
+
+// DELETE /me/tokens/{id}
+func (h *Handler) revokeToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := h.cfg.Users.RevokeToken(r.Context(), userFrom(r.Context()).ID, id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type savedWorkoutDTO struct {
+	Name    string          `json:"name"`
+	Workout *models.Workout `json:"workout"`
+}
+
+func (in savedWorkoutDTO) validate() error {
+	if name := strings.TrimSpace(in.Name); name == "" || len(name) > maxNameLength {
+		return errors.New("name must be 1 to 100 characters")
+	}
+	if in.Workout == nil {
+		return errors.New("workout is required")
+	}
+	return nil
+}
+
+// GET /me/workouts
+func (h *Handler) listWorkouts(w http.ResponseWriter, r *http.Request) {
+	list, err := h.cfg.Users.Workouts(r.Context(), userFrom(r.Context()).ID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"workouts": list})
+}
+
+// POST /me/workouts
+func (h *Handler) saveWorkout(w http.ResponseWriter, r *http.Request) {
+	var in savedWorkoutDTO
+	if err := decodeJSON(w, r, &in); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	if err := in.validate(); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	saved, err := h.cfg.Users.SaveWorkout(r.Context(), userFrom(r.Context()).ID, strings.TrimSpace(in.Name), *in.Workout)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/me/workouts/"+strconv.FormatInt(saved.ID, 10))
+	util.WriteJSON(w, http.StatusCreated, saved)
+}
+
+// GET /me/workouts/{id}
+func (h *Handler) getWorkout(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	saved, err := h.cfg.Users.Workout(r.Context(), userFrom(r.Context()).ID, id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, saved)
+}
+
+// PUT /me/workouts/{id}
+func (h *Handler) updateWorkout(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var in savedWorkoutDTO
+	if err := decodeJSON(w, r, &in); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	if err := in.validate(); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	saved, err := h.cfg.Users.UpdateWorkout(r.Context(), userFrom(r.Context()).ID, id, strings.TrimSpace(in.Name), *in.Workout)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, saved)
+}
+
+// DELETE /me/workouts/{id}
+func (h *Handler) deleteWorkout(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := h.cfg.Users.DeleteWorkout(r.Context(), userFrom(r.Context()).ID, id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /me/favorites
+func (h *Handler) listFavorites(w http.ResponseWriter, r *http.Request) {
+	list, err := h.cfg.Users.Favorites(r.Context(), userFrom(r.Context()).ID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"favorites": list})
+}
+
+// PUT /me/favorites/{exerciseID}
+func (h *Handler) addFavorite(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "exerciseID")
+	if !ok {
+		return
+	}
+	if err := h.cfg.Users.AddFavorite(r.Context(), userFrom(r.Context()).ID, int(id)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /me/favorites/{exerciseID}
+func (h *Handler) removeFavorite(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "exerciseID")
+	if !ok {
+		return
+	}
+	if err := h.cfg.Users.RemoveFavorite(r.Context(), userFrom(r.Context()).ID, int(id)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pathID parses a positive integer URL parameter, answering 400 itself when it is invalid.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 32)
+	if err != nil || id <= 0 {
+		badRequest(w, r, errors.New(name+" must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
+
+// tokenName validates an optional token label.
+func tokenName(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > maxNameLength {
+		return "", errors.New("token name must be at most 100 characters")
+	}
+	if s == "" {
+		s = "default"
+	}
+	return s, nil
+}
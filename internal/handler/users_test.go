@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUserRoutes(t *testing.T) {
+	db, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store, err := repository.NewUserStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := New(nil, jsonlog.New(io.Discard, jsonlog.LevelOff), Config{Users: service.NewUserService(store)})
+
+	do := func(method, target, token, body string, wantStatus int, dst any) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.Router().ServeHTTP(rec, req)
+		if rec.Code != wantStatus {
+			t.Fatalf("%s %s: status %d, want %d: %s", method, target, rec.Code, wantStatus, rec.Body)
+		}
+		if dst != nil {
+			if err := json.NewDecoder(rec.Body).Decode(dst); err != nil {
+				t.Fatalf("%s %s: decode: %v", method, target, err)
+			}
+		}
+	}
+
+	creds := `{"email":"sam@example.com","password":"correct horse"}`
+	do(http.MethodPost, "/users/register", "", creds, http.StatusCreated, nil)
+	do(http.MethodPost, "/users/register", "", creds, http.StatusConflict, nil)
+	do(http.MethodPost, "/users/register", "", `{"email":"x@example.com","password":"short"}`, http.StatusBadRequest, nil)
+	do(http.MethodPost, "/users/login", "", `{"email":"sam@example.com","password":"wrong password"}`, http.StatusUnauthorized, nil)
+
+	var tok models.APIToken
+	do(http.MethodPost, "/users/login", "", creds, http.StatusCreated, &tok)
+	if !strings.HasPrefix(tok.Token, "rbk_") {
+		t.Fatalf("unexpected token %q", tok.Token)
+	}
+
+	do(http.MethodGet, "/me/workouts", "", "", http.StatusUnauthorized, nil)
+	do(http.MethodGet, "/me/workouts", "rbk_nope", "", http.StatusUnauthorized, nil)
+
+	var saved models.SavedWorkout
+	do(http.MethodPost, "/me/workouts", tok.Token, `{"name":"Push","workout":{"muscles":["chest"],"level":"beginner"}}`, http.StatusCreated, &saved)
+	do(http.MethodPut, "/me/workouts/"+strconv.FormatInt(saved.ID, 10), tok.Token, `{"name":"Push A","workout":{"muscles":["chest"]}}`, http.StatusOK, &saved)
+	if saved.Name != "Push A" {
+		t.Fatalf("update not applied: %+v", saved)
+	}
+	do(http.MethodPut, "/me/favorites/192", tok.Token, "", http.StatusNoContent, nil)
+	var favs struct{ Favorites []models.Favorite }
+	do(http.MethodGet, "/me/favorites", tok.Token, "", http.StatusOK, &favs)
+	if len(favs.Favorites) != 1 || favs.Favorites[0].ExerciseID != 192 {
+		t.Fatalf("favorites: %+v", favs)
+	}
+	do(http.MethodDelete, "/me/workouts/"+strconv.FormatInt(saved.ID, 10), tok.Token, "", http.StatusNoContent, nil)
+	do(http.MethodGet, "/me/workouts/"+strconv.FormatInt(saved.ID, 10), tok.Token, "", http.StatusNotFound, nil)
+
+	do(http.MethodDelete, "/me/tokens/"+strconv.FormatInt(tok.ID, 10), tok.Token, "", http.StatusNoContent, nil)
+	do(http.MethodGet, "/me", tok.Token, "", http.StatusUnauthorized, nil)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/service"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+	"net/http"
+	"strings"
+)
+
+const (
+	maxWorkoutMuscles = 5
+	minWorkoutMinutes = 20
+	maxWorkoutMinutes = 180
+	// maxBodyBytes bounds JSON request bodies.
+	maxBodyBytes = 1 << 20
+)
+
+type generateWorkoutDTO struct {
+	Muscles   []string `json:"muscles"`
+	Duration  int      `json:"duration"`
+	Equipment []int    `json:"equipment"`
+	Level     string   `json:"level"`
+}
+
+// POST /workouts/generate
+func (h *Handler) generateWorkout(w http.ResponseWriter, r *http.Request) {
+	var in generateWorkoutDTO
+	if err := decodeJSON(w, r, &in); err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	req, err := in.toRequest()
+	if err != nil {
+		badRequest(w, r, err)
+		return
+	}
+	workout, err := h.svc.GenerateWorkout(r.Context(), req)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if workout.Stale {
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+	}
+	util.WriteJSON(w, http.StatusOK, workout)
+}
+
+func (in generateWorkoutDTO) toRequest() (service.WorkoutRequest, error) {
+	req := service.WorkoutRequest{Minutes: in.Duration, Equipment: in.Equipment}
+	for _, m := range in.Muscles {
+		if m = strings.TrimSpace(m); m != "" {
+			req.Muscles = append(req.Muscles, m)
+		}
+	}
+	if len(req.Muscles) == 0 || len(req.Muscles) > maxWorkoutMuscles {
+		return req, fmt.Errorf("muscles must list 1 to %d muscles", maxWorkoutMuscles)
+	}
+	if req.Minutes == 0 {
+		req.Minutes = 60
+	}
+	if req.Minutes < minWorkoutMinutes || req.Minutes > maxWorkoutMinutes {
+		return req, fmt.Errorf("duration must be between %d and %d minutes", minWorkoutMinutes, maxWorkoutMinutes)
+	}
+	for _, id := range req.Equipment {
+		if id <= 0 {
+			return req, errors.New("equipment must be a list of positive IDs")
+		}
+	}
+	level, ok := service.ParseLevel(strings.ToLower(strings.TrimSpace(in.Level)))
+	if !ok {
+		return req, errors.New("level must be one of: beginner, intermediate, advanced")
+	}
+	req.Level = level
+	return req, nil
+}
+
+// decodeJSON reads a single JSON object from the request body, rejecting unknown fields.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	if dec.More() {
+		return errors.New("invalid JSON body: unexpected data after the object")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"net/http"
+	"testing"
+)
+
+func TestGenerateWorkoutRoute(t *testing.T) {
+	h := newWgerHandler(t)
+	for _, body := range []string{
+		`{}`,
+		`{"muscles":[" "]}`,
+		`{"muscles":["chest","back","biceps","triceps","shoulders","quads"]}`,
+		`{"muscles":["chest"],"duration":10}`,
+		`{"muscles":["chest"],"duration":181}`,
+		`{"muscles":["chest"],"equipment":[0]}`,
+		`{"muscles":["chest"],"level":"expert"}`,
+		`{"muscles":["chest"],"reps":5}`,
+		`{"muscles":"chest"}`,
+	} {
+		serve(t, h, http.MethodPost, "/workouts/generate", body, http.StatusBadRequest, nil)
+	}
+	serve(t, h, http.MethodPost, "/workouts/generate", `{"muscles":["wings"]}`, http.StatusNotFound, nil)
+
+	var w models.Workout
+	serve(t, h, http.MethodPost, "/workouts/generate", `{"muscles":["chest"],"duration":45,"level":"beginner"}`, http.StatusOK, &w)
+	if w.Level != "beginner" || w.DurationMinutes != 45 || len(w.Compounds)+len(w.Accessories) == 0 {
+		t.Fatalf("unexpected workout: %+v", w)
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// KeyValidator resolves an API key to a stable, non-secret name used for rate limiting and usage
+// counters. Unknown keys report ok == false; err is reserved for lookup failures.
+type KeyValidator interface {
+	ValidateKey(ctx context.Context, key string) (name string, ok bool, err error)
+}
+
+// HashToken is how API keys and user tokens are stored and compared: hex-encoded SHA-256.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// FileKeys are API keys listed in a YAML file:
+//
+//	keys:
+//	  - name: coach-app
+//	    sha256: 9f86d081884c7d65...   # preferred: hex SHA-256 of the key
+//	  - name: local-dev
+//	    key: dev-secret               # plain keys are accepted too
+type FileKeys struct {
+	byHash map[string]string
+}
+
+// NewFileKeys loads keys from path.
+func NewFileKeys(path string) (*FileKeys, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		Keys []struct {
+			Name   string `yaml:"name"`
+			Key    string `yaml:"key"`
+			SHA256 string `yaml:"sha256"`
+		} `yaml:"keys"`
+	}
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	k := &FileKeys{byHash: make(map[string]string, len(f.Keys))}
+	for i, e := range f.Keys {
+		hash := strings.ToLower(strings.TrimSpace(e.SHA256))
+		if e.Key != "" {
+			hash = HashToken(e.Key)
+		}
+		if len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("key #%d: set either key or a hex sha256", i+1)
+		}
+		name := e.Name
+		if name == "" {
+			name = "key-" + strconv.Itoa(i+1)
+		}
+		k.byHash[hash] = name
+	}
+	return k, nil
+}
+
+func (k *FileKeys) ValidateKey(_ context.Context, key string) (string, bool, error) {
+	want := HashToken(key)
+	for hash, name := range k.byHash {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(want)) == 1 {
+			return name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Len reports how many keys were loaded.
+func (k *FileKeys) Len() int {
+	return len(k.byHash)
+}
+
+// ValidateKey accepts the API tokens users get from /users/login; the name is "user:<id>".
+func (s *UserStore) ValidateKey(ctx context.Context, key string) (string, bool, error) {
+	u, err := s.UserByToken(ctx, HashToken(key))
+	if errors.Is(err, models.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return "user:" + strconv.FormatInt(u.ID, 10), true, nil
+}
+
+// KeyChain asks each validator in turn.
+type KeyChain []KeyValidator
+
+func (c KeyChain) ValidateKey(ctx context.Context, key string) (string, bool, error) {
+	var errs []error
+	for _, v := range c {
+		name, ok, err := v.ValidateKey(ctx, key)
+		if ok {
+			return name, true, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return "", false, errors.Join(errs...)
+}
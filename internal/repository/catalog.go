@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExerciseStore mirrors exercises fetched from wger into SQLite so they survive restarts
+// and can be served when wger is slow or down.
+type ExerciseStore struct {
+	db *sql.DB
+}
+
+const catalogSchema = `
+CREATE TABLE IF NOT EXISTS exercises (
+	id          INTEGER PRIMARY KEY,
+	name        TEXT    NOT NULL DEFAULT '',
+	description TEXT    NOT NULL DEFAULT '',
+	category_id INTEGER NOT NULL DEFAULT 0,
+	updated_at  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS categories (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS equipment (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS exercise_muscles (
+	exercise_id INTEGER NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+	muscle_id   INTEGER NOT NULL,
+	is_primary  INTEGER NOT NULL,
+	PRIMARY KEY (exercise_id, muscle_id, is_primary)
+);
+CREATE INDEX IF NOT EXISTS idx_exercise_muscles_muscle ON exercise_muscles(muscle_id);
+CREATE TABLE IF NOT EXISTS exercise_equipment (
+	exercise_id  INTEGER NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+	equipment_id INTEGER NOT NULL,
+	PRIMARY KEY (exercise_id, equipment_id)
+);
+`
+
+// NewExerciseStore creates the catalog tables if needed.
+func NewExerciseStore(ctx context.Context, db *sql.DB) (*ExerciseStore, error) {
+	if db == nil {
+		return nil, errors.New("nil database")
+	}
+	if _, err := db.ExecContext(ctx, catalogSchema); err != nil {
+		return nil, err
+	}
+	return &ExerciseStore{db: db}, nil
+}
+
+// UpsertExercises stores exercises together with their muscle and equipment references.
+// Category and equipment names are stored separately (see UpsertCategories and UpsertEquipment).
+func (s *ExerciseStore) UpsertExercises(ctx context.Context, exs []models.Exercise) (err error) {
+	if len(exs) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	now := time.Now().Unix()
+	for _, e := range exs {
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO exercises (id, name, description, category_id, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				name = excluded.name,
+				description = excluded.description,
+				category_id = excluded.category_id,
+				updated_at = excluded.updated_at`,
+			e.ID, e.Name, e.Description, e.Category, now); err != nil {
+			return err
+		}
+
+		// relations are replaced wholesale so removed muscles/equipment don't linger
+		if _, err = tx.ExecContext(ctx, `DELETE FROM exercise_muscles WHERE exercise_id = ?`, e.ID); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, `DELETE FROM exercise_equipment WHERE exercise_id = ?`, e.ID); err != nil {
+			return err
+		}
+		if err = insertMuscles(ctx, tx, e.ID, e.Muscles, true); err != nil {
+			return err
+		}
+		if err = insertMuscles(ctx, tx, e.ID, e.MusclesSecondary, false); err != nil {
+			return err
+		}
+		for _, eq := range e.Equipment {
+			if _, err = tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO exercise_equipment (exercise_id, equipment_id) VALUES (?, ?)`,
+				e.ID, eq); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func insertMuscles(ctx context.Context, tx *sql.Tx, exerciseID int, muscles []int, primary bool) error {
+	for _, m := range muscles {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO exercise_muscles (exercise_id, muscle_id, is_primary) VALUES (?, ?, ?)`,
+			exerciseID, m, primary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExercisesByMuscles returns stored exercises hitting any of the muscles (primary or secondary), ordered by ID,
+// and when the least recently refreshed of them was last written.
+// A limit <= 0 returns every match.
+func (s *ExerciseStore) ExercisesByMuscles(ctx context.Context, muscles []int, limit int) ([]models.Exercise, time.Time, error) {
+	var oldest time.Time
+	if len(muscles) == 0 {
+		return nil, oldest, errors.New("no muscles provided")
+	}
+	args := make([]any, 0, len(muscles)+1)
+	for _, m := range muscles {
+		args = append(args, m)
+	}
+	query := `
+		SELECT id, name, description, category_id, updated_at FROM exercises
+		WHERE id IN (SELECT exercise_id FROM exercise_muscles WHERE muscle_id IN (` + placeholders(len(muscles)) + `))
+		ORDER BY id`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, oldest, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []models.Exercise
+	index := make(map[int]int)
+	for rows.Next() {
+		var e models.Exercise
+		var updated int64
+		if err := rows.Scan(&e.ID, &e.Name, &e.Description, &e.Category, &updated); err != nil {
+			return nil, oldest, err
+		}
+		if t := time.Unix(updated, 0); oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+		index[e.ID] = len(out)
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, oldest, err
+	}
+	if len(out) == 0 {
+		return out, oldest, nil
+	}
+	if err := s.loadRelations(ctx, out, index); err != nil {
+		return nil, oldest, err
+	}
+	return out, oldest, nil
+}
+
+func (s *ExerciseStore) loadRelations(ctx context.Context, out []models.Exercise, index map[int]int) error {
+	ids := make([]any, 0, len(out))
+	for _, e := range out {
+		ids = append(ids, e.ID)
+	}
+	in := placeholders(len(ids))
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT exercise_id, muscle_id, is_primary FROM exercise_muscles WHERE exercise_id IN (`+in+`) ORDER BY exercise_id, muscle_id`,
+		ids...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var exID, muscleID int
+		var primary bool
+		if err := rows.Scan(&exID, &muscleID, &primary); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		e := &out[index[exID]]
+		if primary {
+			e.Muscles = append(e.Muscles, muscleID)
+		} else {
+			e.MusclesSecondary = append(e.MusclesSecondary, muscleID)
+		}
+	}
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return err
+	}
+
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT exercise_id, equipment_id FROM exercise_equipment WHERE exercise_id IN (`+in+`) ORDER BY exercise_id, equipment_id`,
+		ids...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var exID, eqID int
+		if err := rows.Scan(&exID, &eqID); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		e := &out[index[exID]]
+		e.Equipment = append(e.Equipment, eqID)
+	}
+	return errors.Join(rows.Err(), rows.Close())
+}
+
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// UpsertCategories stores category names.
+func (s *ExerciseStore) UpsertCategories(ctx context.Context, items []models.Lookup) error {
+	return s.upsertNamed(ctx, `INSERT INTO categories (id, name) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name`, items)
+}
+
+// UpsertEquipment stores equipment names.
+func (s *ExerciseStore) UpsertEquipment(ctx context.Context, items []models.Lookup) error {
+	return s.upsertNamed(ctx, `INSERT INTO equipment (id, name) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name`, items)
+}
+
+// Categories returns stored categories that have a name.
+func (s *ExerciseStore) Categories(ctx context.Context) ([]models.Lookup, error) {
+	return s.named(ctx, `SELECT id, name FROM categories WHERE name <> '' ORDER BY id`)
+}
+
+// Equipment returns stored equipment that has a name.
+func (s *ExerciseStore) Equipment(ctx context.Context) ([]models.Lookup, error) {
+	return s.named(ctx, `SELECT id, name FROM equipment WHERE name <> '' ORDER BY id`)
+}
+
+func (s *ExerciseStore) upsertNamed(ctx context.Context, stmt string, items []models.Lookup) (err error) {
+	if len(items) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+	for _, it := range items {
+		if _, err = tx.ExecContext(ctx, stmt, it.ID, it.Name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *ExerciseStore) named(ctx context.Context, query string) ([]models.Lookup, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []models.Lookup
+	for rows.Next() {
+		var it models.Lookup
+		if err := rows.Scan(&it.ID, &it.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// ExerciseIDsByMuscle lists, per muscle, the stored exercises hitting it (primary or secondary).
+// Muscles without exercises are missing from the map.
+func (s *ExerciseStore) ExerciseIDsByMuscle(ctx context.Context, muscles []int) (map[int][]int, error) {
+	out := map[int][]int{}
+	if len(muscles) == 0 {
+		return out, nil
+	}
+	args := make([]any, 0, len(muscles))
+	for _, m := range muscles {
+		args = append(args, m)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT muscle_id, group_concat(DISTINCT exercise_id) FROM exercise_muscles
+		WHERE muscle_id IN (`+placeholders(len(muscles))+`) GROUP BY muscle_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var muscle int
+		var ids string
+		if err := rows.Scan(&muscle, &ids); err != nil {
+			return nil, err
+		}
+		for _, part := range strings.Split(ids, ",") {
+			if id, err := strconv.Atoi(part); err == nil {
+				out[muscle] = append(out[muscle], id)
+			}
+		}
+	}
+	return out, rows.Err()
+}
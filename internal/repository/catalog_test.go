@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestDB(t *testing.T) *ExerciseStore {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := NewExerciseStore(context.Background(), db)
+	if err != nil {
+		t.Fatalf("NewExerciseStore: %v", err)
+	}
+	return store
+}
+
+func TestExerciseStore_UpsertAndQuery(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDB(t)
+
+	in := []models.Exercise{
+		{ID: 3, Name: "Curl", Category: 8, Muscles: []int{1}, MusclesSecondary: []int{9}, Equipment: []int{3}},
+		{ID: 1, Name: "Bench Press", Category: 11, Muscles: []int{4}, MusclesSecondary: []int{2, 5}, Equipment: []int{1, 8}},
+		{ID: 2, Name: "Dips", Category: 11, Muscles: []int{5}, MusclesSecondary: []int{4}},
+	}
+	before := time.Now().Truncate(time.Second)
+	if err := store.UpsertExercises(ctx, in); err != nil {
+		t.Fatalf("UpsertExercises: %v", err)
+	}
+
+	got, updatedAt, err := store.ExercisesByMuscles(ctx, []int{4}, 0)
+	if err != nil {
+		t.Fatalf("ExercisesByMuscles: %v", err)
+	}
+	if updatedAt.Before(before) || updatedAt.After(time.Now()) {
+		t.Fatalf("unexpected updated_at %v", updatedAt)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 2 {
+		t.Fatalf("expected exercises 1 and 2 ordered by id, got %+v", got)
+	}
+	if !reflect.DeepEqual(got[0].MusclesSecondary, []int{2, 5}) || !reflect.DeepEqual(got[0].Equipment, []int{1, 8}) {
+		t.Fatalf("relations not restored: %+v", got[0])
+	}
+
+	// re-upserting replaces relations rather than accumulating them
+	in[1].Equipment = []int{3}
+	if err := store.UpsertExercises(ctx, in[1:2]); err != nil {
+		t.Fatalf("UpsertExercises (update): %v", err)
+	}
+	got, _, err = store.ExercisesByMuscles(ctx, []int{4}, 1)
+	if err != nil {
+		t.Fatalf("ExercisesByMuscles: %v", err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Equipment, []int{3}) {
+		t.Fatalf("expected updated equipment [3] with limit 1, got %+v", got)
+	}
+
+	// names only come from the lookup tables, never as bare IDs from exercises
+	if cats, err := store.Categories(ctx); err != nil || len(cats) != 0 {
+		t.Fatalf("expected no categories before names are stored, got %+v %v", cats, err)
+	}
+	if err := store.UpsertCategories(ctx, []models.Lookup{{ID: 11, Name: "Chest"}}); err != nil {
+		t.Fatalf("UpsertCategories: %v", err)
+	}
+	if cats, err := store.Categories(ctx); err != nil || !reflect.DeepEqual(cats, []models.Lookup{{ID: 11, Name: "Chest"}}) {
+		t.Fatalf("unexpected categories %+v %v", cats, err)
+	}
+}
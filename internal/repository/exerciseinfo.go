@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+)
+
+// ExerciseSchema selects the wger endpoint exercises are read from.
+type ExerciseSchema string
+
+const (
+	// SchemaExercise is the legacy /exercise/ endpoint with name and description on the exercise.
+	SchemaExercise ExerciseSchema = "exercise"
+	// SchemaExerciseInfo is /exerciseinfo/, where names and descriptions live in per-language
+	// translations. Current wger versions need it: their /exercise/ has no names.
+	SchemaExerciseInfo ExerciseSchema = "exerciseinfo"
+)
+
+// ParseExerciseSchema validates a schema name.
+func ParseExerciseSchema(s string) (ExerciseSchema, bool) {
+	switch sc := ExerciseSchema(s); sc {
+	case SchemaExercise, SchemaExerciseInfo:
+		return sc, true
+	}
+	return "", false
+}
+
+// WithSchema switches the endpoint FetchExercises reads from (SchemaExercise by default).
+func (c *WgerClient) WithSchema(s ExerciseSchema) *WgerClient {
+	c.schema = s
+	return c
+}
+
+type wgerRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type wgerLicense struct {
+	ShortName string `json:"short_name"`
+	FullName  string `json:"full_name"`
+	URL       string `json:"url"`
+}
+
+type wgerTranslation struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Language      int    `json:"language"`
+	LicenseAuthor string `json:"license_author"`
+	Aliases       []struct {
+		Alias string `json:"alias"`
+	} `json:"aliases"`
+}
+
+type wgerMedia struct {
+	Image         string `json:"image"`
+	Video         string `json:"video"`
+	IsMain        bool   `json:"is_main"`
+	LicenseAuthor string `json:"license_author"`
+}
+
+// wgerExerciseInfo is an item of /exerciseinfo/. References are nested objects, not IDs.
+type wgerExerciseInfo struct {
+	ID               int               `json:"id"`
+	Category         wgerRef           `json:"category"`
+	Muscles          []wgerRef         `json:"muscles"`
+	MusclesSecondary []wgerRef         `json:"muscles_secondary"`
+	Equipment        []wgerRef         `json:"equipment"`
+	License          *wgerLicense      `json:"license"`
+	LicenseAuthor    string            `json:"license_author"`
+	Images           []wgerMedia       `json:"images"`
+	Videos           []wgerMedia       `json:"videos"`
+	Translations     []wgerTranslation `json:"translations"`
+}
+
+// model maps the exercise with every translation, localized into language (see Localize).
+func (e wgerExerciseInfo) model(language int) models.Exercise {
+	out := models.Exercise{
+		ID:               e.ID,
+		Category:         e.Category.ID,
+		Muscles:          refIDs(e.Muscles),
+		MusclesSecondary: refIDs(e.MusclesSecondary),
+		Equipment:        refIDs(e.Equipment),
+		Author:           e.LicenseAuthor,
+	}
+	if e.License != nil {
+		name := e.License.ShortName
+		if name == "" {
+			name = e.License.FullName
+		}
+		out.License = &models.License{Name: name, URL: e.License.URL}
+	}
+	for _, img := range e.Images {
+		out.Images = append(out.Images, models.Media{URL: img.Image, IsMain: img.IsMain, Author: img.LicenseAuthor})
+	}
+	for _, v := range e.Videos {
+		out.Videos = append(out.Videos, models.Media{URL: v.Video, IsMain: v.IsMain, Author: v.LicenseAuthor})
+	}
+	for _, t := range e.Translations {
+		tr := models.Translation{
+			Language:    LanguageCode(t.Language),
+			Name:        t.Name,
+			Description: t.Description,
+			Author:      t.LicenseAuthor,
+		}
+		for _, a := range t.Aliases {
+			tr.Aliases = append(tr.Aliases, a.Alias)
+		}
+		out.Translations = append(out.Translations, tr)
+	}
+	return Localize(out, language)
+}
+
+// Localize takes name, description, aliases and author from the exercise's translation in language,
+// else English, else the first one. Exercises without translations are returned as they are.
+func Localize(e models.Exercise, language int) models.Exercise {
+	want, english := LanguageCode(language), LanguageCode(englishLanguage)
+	var chosen *models.Translation
+	for i := range e.Translations {
+		t := &e.Translations[i]
+		switch {
+		case t.Language == want:
+			chosen = t
+		case t.Language == english && (chosen == nil || chosen.Language != want):
+			chosen = t
+		case chosen == nil:
+			chosen = t
+		}
+	}
+	if chosen == nil {
+		return e
+	}
+	e.Name = chosen.Name
+	e.Description = chosen.Description
+	e.Language = chosen.Language
+	e.Aliases = chosen.Aliases
+	if chosen.Author != "" {
+		e.Author = chosen.Author
+	}
+	return e
+}
+
+func refIDs(refs []wgerRef) []int {
+	ids := make([]int, 0, len(refs))
+	for _, r := range refs {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
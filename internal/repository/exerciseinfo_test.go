@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// exerciseInfoPage is trimmed from a wger /exerciseinfo/ response.
+const exerciseInfoPage = `{"count":2,"next":null,"previous":null,"results":[
+ {"id":73,"uuid":"0ec76f5d-1311-4d6d-bf79-00fa31d79a3a",
+  "category":{"id":11,"name":"Chest"},
+  "muscles":[{"id":4,"name":"Pectoralis major","name_en":"Chest","is_front":true}],
+  "muscles_secondary":[{"id":2,"name":"Anterior deltoid","name_en":"Shoulders","is_front":true},{"id":5,"name":"Triceps brachii","name_en":"Triceps","is_front":false}],
+  "equipment":[{"id":1,"name":"Barbell"},{"id":8,"name":"Bench"}],
+  "license":{"id":2,"full_name":"Creative Commons Attribution Share Alike 4","short_name":"CC-BY-SA 4","url":"https://creativecommons.org/licenses/by-sa/4.0/deed.en"},
+  "license_author":"wger.de",
+  "images":[{"id":1,"image":"https://wger.de/media/exercise-images/192/Bench-press-1.png","is_main":true,"license_author":"Everkinetic"}],
+  "videos":[],
+  "translations":[
+   {"id":192,"name":"Bankdrücken LH","description":"<p>Auf eine Bank legen …</p>","language":1,"aliases":[],"license_author":""},
+   {"id":73,"name":"Bench Press","description":"<p>Lay down on a bench …</p>","language":2,
+    "aliases":[{"id":1,"alias":"Barbell bench press"}],"license_author":"deusinvictus"}]},
+ {"id":1910,"category":{"id":11,"name":"Chest"},"muscles":[{"id":4}],"muscles_secondary":[],"equipment":[],
+  "license":null,"license_author":"","images":[],
+  "videos":[{"id":9,"video":"https://wger.de/media/exercise-video/1910/push-up.mp4","is_main":true}],
+  "translations":[{"id":2001,"name":"Flexiones","description":"","language":4,"aliases":[]}]}]}`
+
+func TestFetchExercises_ExerciseInfoSchema(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exerciseinfo/" || r.URL.Query().Has("language") {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.URL.Query().Get("muscles") == "" {
+			_, _ = io.WriteString(w, `{"count":0,"results":[]}`)
+			return
+		}
+		_, _ = io.WriteString(w, exerciseInfoPage)
+	}))
+	defer srv.Close()
+	c := NewWgerClient(srv.Client(), srv.URL, 2, "test").WithSchema(SchemaExerciseInfo)
+
+	got, err := c.FetchExercises(context.Background(), []int{4}, 20, ExerciseFilter{Language: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 exercises, got %d", len(got))
+	}
+	bench, pushUp := got[0], got[1]
+	if bench.Name != "Bankdrücken LH" || bench.Language != "de" || bench.Category != 11 {
+		t.Fatalf("expected the German translation, got %+v", bench)
+	}
+	if !slices.Equal(bench.MusclesSecondary, []int{2, 5}) || !slices.Equal(bench.Equipment, []int{1, 8}) {
+		t.Fatalf("references not mapped to IDs: %+v", bench)
+	}
+	if bench.License == nil || bench.License.Name != "CC-BY-SA 4" || bench.Author != "wger.de" ||
+		len(bench.Images) != 1 || !bench.Images[0].IsMain || len(bench.Translations) != 2 {
+		t.Fatalf("license, author, images or translations missing: %+v", bench)
+	}
+	// no German or English translation: the only one there is
+	if pushUp.Name != "Flexiones" || pushUp.Language != "es" || len(pushUp.Videos) != 1 || pushUp.License != nil {
+		t.Fatalf("unexpected fallback mapping: %+v", pushUp)
+	}
+
+	got, err = c.FetchExercises(context.Background(), []int{4}, 20, ExerciseFilter{Language: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Name != "Bench Press" || got[0].Author != "deusinvictus" || !slices.Equal(got[0].Aliases, []string{"Barbell bench press"}) {
+		t.Fatalf("expected the English fallback with its aliases and author, got %+v", got[0])
+	}
+}
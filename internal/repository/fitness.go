@@ -1,20 +1,20 @@
 package repository
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/m4rk1sov/rbk-api/internal/domain/models"
-	"io"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-
-	_ "modernc.org/sqlite"
 )
 
 type WgerClient struct {
@@ -22,6 +22,9 @@ type WgerClient struct {
 	baseURL    string
 	language   int
 	userAgent  string
+	maxPages   int
+	schema     ExerciseSchema
+	logger     *jsonlog.Logger
 }
 
 func NewWgerClient(httpClient *http.Client, baseURL string, language int, userAgent string) *WgerClient {
@@ -55,7 +58,35 @@ func NewWgerClient(httpClient *http.Client, baseURL string, language int, userAg
 		baseURL:    strings.TrimRight(baseURL, "/"),
 		language:   language,
 		userAgent:  userAgent,
+		maxPages:   1,
+		schema:     SchemaExercise,
+	}
+}
+
+// WithMaxPages enables paginated fetching: every wger call follows `next` links
+// until the last page or until n pages were read. n <= 1 keeps single-page mode.
+func (c *WgerClient) WithMaxPages(n int) *WgerClient {
+	if n < 1 {
+		n = 1
 	}
+	c.maxPages = n
+	return c
+}
+
+// Language is the wger language ID used unless a query asks for another one.
+func (c *WgerClient) Language() int {
+	return c.language
+}
+
+// Schema is the endpoint exercises are read from.
+func (c *WgerClient) Schema() ExerciseSchema {
+	return c.schema
+}
+
+// WithLogger records the latency of every wger query leg.
+func (c *WgerClient) WithLogger(logger *jsonlog.Logger) *WgerClient {
+	c.logger = logger
+	return c
 }
 
 type wgerExercise struct {
@@ -66,77 +97,108 @@ type wgerExercise struct {
 	Muscles          []int  `json:"muscles"`
 	MusclesSecondary []int  `json:"muscles_secondary"`
 	Equipment        []int  `json:"equipment"`
+	ExerciseBase     int    `json:"exercise_base"`
 }
 
-type wgerPagedResponse struct {
-	Count    int            `json:"count"`
-	Next     *string        `json:"next"`
-	Previous *string        `json:"previous"`
-	Results  []wgerExercise `json:"results"`
+// wgerPage is one page of a wger list endpoint.
+type wgerPage[T any] struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []T     `json:"results"`
 }
 
-// FetchExercises fetches from primary and secondary muscles and merges results (deduplicated by ID).
-func (c *WgerClient) FetchExercises(ctx context.Context, muscles []int, limit int) ([]models.Exercise, error) {
+type wgerPagedResponse = wgerPage[wgerExercise]
+
+// ExerciseFilter is the part of an exercise query wger can evaluate itself. Zero values don't filter.
+type ExerciseFilter struct {
+	Category      int
+	Equipment     int
+	SkipPrimary   bool // don't query the `muscles` leg
+	SkipSecondary bool // don't query the `muscles_secondary` leg
+	// Language is the wger language ID of names and descriptions; 0 uses the client's language.
+	Language int
+}
+
+// FetchExercises fetches from primary and secondary muscles concurrently and merges results
+// (deduplicated and ordered by ID).
+// limit is the wger page size; in paginated mode (see WithMaxPages) every page up to the cap is read.
+//
+// A failed primary leg fails the call. When only the secondary leg fails, the primary results are
+// returned together with a *models.PartialError.
+func (c *WgerClient) FetchExercises(ctx context.Context, muscles []int, limit int, f ExerciseFilter) ([]models.Exercise, error) {
 	if len(muscles) == 0 {
-		return nil, errors.New("no muscles provided")
+		return nil, fmt.Errorf("%w: no muscles provided", models.ErrInvalidIDs)
 	}
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
+	// a failed primary leg makes the secondary one pointless
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	// Helper to call endpoint with given query
-	call := func(param string, muscleIDs []int) ([]wgerExercise, error) {
-		u, err := url.Parse(c.baseURL + "/exercise/")
+	// Helper to call endpoint with given query, following `next` links in paginated mode
+	language := cmp.Or(f.Language, c.language)
+	call := func(param string, muscleIDs []int) ([]models.Exercise, error) {
+		u, err := url.Parse(c.baseURL + "/" + string(c.schema) + "/")
 		if err != nil {
 			return nil, err
 		}
 
 		q := u.Query()
-		q.Set("language", strconv.Itoa(c.language))
+		if c.schema == SchemaExercise {
+			// exerciseinfo carries every translation; one is picked per exercise instead
+			q.Set("language", strconv.Itoa(language))
+		}
 		q.Set("limit", strconv.Itoa(limit))
 		// wger supports filter by 'muscles' and 'muscles_secondary'
 		q.Set(param, intsToCSV(muscleIDs))
-		u.RawQuery = q.Encode()
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-		if err != nil {
-			return nil, err
+		if f.Category > 0 {
+			q.Set("category", strconv.Itoa(f.Category))
 		}
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("User-Agent", c.userAgent)
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer func(Body io.ReadCloser) {
-			if closeErr := Body.Close(); closeErr != nil {
-				err = errors.Join(err, closeErr)
-			}
-		}(resp.Body)
-
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return nil, fmt.Errorf("wger returned %d", resp.StatusCode)
+		if f.Equipment > 0 {
+			q.Set("equipment", strconv.Itoa(f.Equipment))
 		}
+		u.RawQuery = q.Encode()
 
-		var pr wgerPagedResponse
-		if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
-			return nil, err
-		}
-		return pr.Results, nil
+		start := time.Now()
+		out, err := c.readExercises(ctx, u.String(), language)
+		c.logLeg(param, muscleIDs, len(out), time.Since(start), err)
+		return out, err
 	}
 
-	primary, err := call("muscles", muscles)
-	if err != nil {
-		return nil, err
+	var primary, secondary []models.Exercise
+	var primaryErr, secondaryErr error
+	var wg sync.WaitGroup
+	if !f.SkipPrimary {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if primary, primaryErr = call("muscles", muscles); primaryErr != nil {
+				cancel()
+			}
+		}()
 	}
-	secondary, err := call("muscles_secondary", muscles)
-	if err != nil {
-		// secondary may be empty
-		return nil, err
+	if !f.SkipSecondary {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			secondary, secondaryErr = call("muscles_secondary", muscles)
+		}()
+	}
+	wg.Wait()
+
+	var partial error
+	switch {
+	case primaryErr != nil:
+		return nil, primaryErr
+	case secondaryErr != nil && f.SkipPrimary:
+		return nil, secondaryErr
+	case secondaryErr != nil:
+		partial = &models.PartialError{Leg: "muscles_secondary", Err: secondaryErr}
 	}
 
-	merged := make(map[int]wgerExercise, len(primary)+len(secondary))
+	merged := make(map[int]models.Exercise, len(primary)+len(secondary))
 	for _, e := range primary {
 		merged[e.ID] = e
 	}
@@ -145,18 +207,58 @@ func (c *WgerClient) FetchExercises(ctx context.Context, muscles []int, limit in
 	}
 
 	out := make([]models.Exercise, 0, len(merged))
-	for _, e := range merged {
-		out = append(out, models.Exercise{
-			ID:               e.ID,
-			Name:             e.Name,
-			Description:      e.Description,
-			Category:         e.Category,
-			Muscles:          e.Muscles,
-			MusclesSecondary: e.MusclesSecondary,
-			Equipment:        e.Equipment,
-		})
-	}
-	return out, nil
+	for _, id := range slices.Sorted(maps.Keys(merged)) {
+		out = append(out, merged[id])
+	}
+	return out, partial
+}
+
+// readExercises reads every page of an exercise list in the client's schema.
+func (c *WgerClient) readExercises(ctx context.Context, u string, language int) ([]models.Exercise, error) {
+	var out []models.Exercise
+	if c.schema == SchemaExerciseInfo {
+		it := pagesOf[wgerExerciseInfo](c, u)
+		for it.Next(ctx) {
+			for _, e := range it.Results() {
+				out = append(out, e.model(language))
+			}
+		}
+		return out, it.Err()
+	}
+	it := c.pages(u)
+	for it.Next(ctx) {
+		for _, e := range it.Results() {
+			out = append(out, models.Exercise{
+				ID:               e.ID,
+				Name:             e.Name,
+				Description:      e.Description,
+				Category:         e.Category,
+				Muscles:          e.Muscles,
+				MusclesSecondary: e.MusclesSecondary,
+				Equipment:        e.Equipment,
+				BaseID:           e.ExerciseBase,
+			})
+		}
+	}
+	return out, it.Err()
+}
+
+func (c *WgerClient) logLeg(leg string, muscles []int, results int, took time.Duration, err error) {
+	if c.logger == nil {
+		return
+	}
+	props := map[string]string{
+		"leg":     leg,
+		"muscles": intsToCSV(muscles),
+		"results": strconv.Itoa(results),
+		"time":    took.String(),
+	}
+	if err != nil {
+		props["error"] = err.Error()
+		c.logger.PrintError("wger query failed", props)
+		return
+	}
+	c.logger.PrintInfo("wger query done", props)
 }
 
 func intsToCSV(v []int) string {
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// wgerLanguages maps ISO 639-1 codes to wger language IDs.
+var wgerLanguages = map[string]int{
+	"de": 1, "en": 2, "bg": 3, "es": 4, "ru": 5, "nl": 6, "pt": 7, "el": 8,
+	"cs": 9, "sv": 10, "no": 11, "fr": 12, "it": 13, "pl": 14, "uk": 15, "tr": 16,
+}
+
+// englishLanguage is wger's language ID for English, used when an exercise lacks the requested translation.
+const englishLanguage = 2
+
+// LanguageID maps a language code ("de", "pt-BR") to its wger ID.
+func LanguageID(code string) (int, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	if base == "nb" || base == "nn" {
+		base = "no"
+	}
+	id, ok := wgerLanguages[base]
+	return id, ok
+}
+
+// LanguageCode is the inverse of LanguageID; unknown IDs are returned as numbers.
+func LanguageCode(id int) string {
+	for code, v := range wgerLanguages {
+		if v == id {
+			return code
+		}
+	}
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"net/url"
+	"strconv"
+)
+
+// FetchCategories returns every exercise category known to wger.
+func (c *WgerClient) FetchCategories(ctx context.Context) ([]models.Lookup, error) {
+	return fetchList[models.Lookup](ctx, c, "/exercisecategory/")
+}
+
+// FetchEquipment returns every equipment item known to wger.
+func (c *WgerClient) FetchEquipment(ctx context.Context) ([]models.Lookup, error) {
+	return fetchList[models.Lookup](ctx, c, "/equipment/")
+}
+
+// FetchMuscles returns every muscle known to wger.
+func (c *WgerClient) FetchMuscles(ctx context.Context) ([]models.Muscle, error) {
+	return fetchList[models.Muscle](ctx, c, "/muscle/")
+}
+
+// fetchList reads a small wger list endpoint. A page of 100 holds wger's reference tables;
+// in paginated mode (see WithMaxPages) further pages are read up to the cap.
+func fetchList[T any](ctx context.Context, c *WgerClient, path string) ([]T, error) {
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("limit", strconv.Itoa(100))
+	u.RawQuery = q.Encode()
+
+	var out []T
+	it := pagesOf[T](c, u.String())
+	for it.Next(ctx) {
+		out = append(out, it.Results()...)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -1,8 +1,18 @@
 package repository
 
 import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/cache"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
 	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+	"math"
+	"net"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,3 +29,200 @@ func RequestLogger(logger *jsonlog.Logger) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// Usage buckets for requests that can't be attributed to a key: no key at all, a key that was
+// rejected, and a key that was never checked because the client IP was already over its limit.
+const (
+	anonymousKey  = "anonymous"
+	invalidKey    = "invalid"
+	unverifiedKey = "unverified"
+)
+
+// keyCacheTTL bounds how long a validated (or rejected) key is remembered, and so how long a
+// revoked key keeps working.
+const keyCacheTTL = 30 * time.Second
+
+// APIKeyConfig configures APIKeys.
+type APIKeyConfig struct {
+	// Keys validates `Authorization: Bearer <key>`; nil accepts no keys.
+	Keys KeyValidator
+	// Required rejects requests without a valid key, except on Public paths.
+	Required bool
+	// Public lists path prefixes that never need a key (they are still limited per IP).
+	Public []string
+	// PerKey and PerIP limit requests; nil disables that limit.
+	PerKey *RateLimiter
+	PerIP  *RateLimiter
+	// TrustProxy takes the client IP from the first X-Forwarded-For entry.
+	TrustProxy bool
+	Logger     *jsonlog.Logger
+}
+
+// APIKeys authenticates API keys, applies per-key and per-IP rate limits and counts usage per key.
+type APIKeys struct {
+	cfg   APIKeyConfig
+	known *cache.LRU[keyResult]
+
+	mu    sync.Mutex
+	usage map[string]*models.KeyUsage
+}
+
+type keyResult struct {
+	name string
+	ok   bool
+}
+
+func NewAPIKeys(cfg APIKeyConfig) *APIKeys {
+	return &APIKeys{
+		cfg:   cfg,
+		known: cache.NewLRU[keyResult](1024, keyCacheTTL),
+		usage: make(map[string]*models.KeyUsage),
+	}
+}
+
+// Middleware enforces the configuration. The per-IP limit is checked first, so floods of missing or
+// invalid keys are throttled before any key lookup. Invalid keys get 401 (ignored on public paths),
+// exhausted buckets 429 with Retry-After.
+func (a *APIKeys) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		public := a.isPublic(r.URL.Path)
+		key, hasKey := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		hasKey = hasKey && key != ""
+
+		if ok, wait := a.cfg.PerIP.Allow(a.clientIP(r)); !ok {
+			a.count(a.cachedName(key, hasKey), http.StatusTooManyRequests)
+			tooManyRequests(w, r, wait)
+			return
+		}
+
+		name := anonymousKey
+		if hasKey {
+			res, err := a.validate(r.Context(), key)
+			switch {
+			case err != nil && !public:
+				a.cfg.Logger.PrintError("api key lookup failed", map[string]string{"error": err.Error()})
+				a.count(unverifiedKey, http.StatusServiceUnavailable)
+				util.WriteProblem(w, r, http.StatusServiceUnavailable, "could not validate the API key")
+				return
+			case res.ok:
+				name = res.name
+			case !public:
+				a.count(invalidKey, http.StatusUnauthorized)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				util.WriteProblem(w, r, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+		}
+		if name == anonymousKey && a.cfg.Required && !public {
+			a.count(anonymousKey, http.StatusUnauthorized)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			util.WriteProblem(w, r, http.StatusUnauthorized, "an API key is required: send Authorization: Bearer <key>")
+			return
+		}
+
+		if name != anonymousKey {
+			if ok, wait := a.cfg.PerKey.Allow(name); !ok {
+				a.count(name, http.StatusTooManyRequests)
+				tooManyRequests(w, r, wait)
+				return
+			}
+		}
+		a.count(name, http.StatusOK)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tooManyRequests(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	util.WriteProblem(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+}
+
+// Usage returns the counters per key name, busiest first.
+func (a *APIKeys) Usage() []models.KeyUsage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]models.KeyUsage, 0, len(a.usage))
+	for _, u := range a.usage {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func (a *APIKeys) validate(ctx context.Context, key string) (keyResult, error) {
+	hash := HashToken(key)
+	if res, _, ok := a.known.Get(hash); ok {
+		return res, nil
+	}
+	if a.cfg.Keys == nil {
+		return keyResult{}, nil
+	}
+	name, ok, err := a.cfg.Keys.ValidateKey(ctx, key)
+	if err != nil {
+		return keyResult{}, err
+	}
+	res := keyResult{name: name, ok: ok}
+	a.known.Set(hash, res)
+	return res, nil
+}
+
+// cachedName attributes a request to its key without a lookup: only keys validated recently count.
+func (a *APIKeys) cachedName(key string, hasKey bool) string {
+	if !hasKey {
+		return anonymousKey
+	}
+	if res, _, ok := a.known.Get(HashToken(key)); ok {
+		if res.ok {
+			return res.name
+		}
+		return invalidKey
+	}
+	return unverifiedKey
+}
+
+// count records a request under name; status is what the middleware answered (200 when it passed).
+func (a *APIKeys) count(name string, status int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u, ok := a.usage[name]
+	if !ok {
+		u = &models.KeyUsage{Key: name}
+		a.usage[name] = u
+	}
+	u.Requests++
+	switch status {
+	case http.StatusTooManyRequests:
+		u.Limited++
+	case http.StatusUnauthorized:
+		u.Rejected++
+	}
+	u.LastSeen = time.Now().UTC()
+}
+
+func (a *APIKeys) isPublic(path string) bool {
+	for _, p := range a.cfg.Public {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *APIKeys) clientIP(r *http.Request) string {
+	if a.cfg.TrustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_RefillsOverTime(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("k"); !ok {
+			t.Fatalf("request %d within burst was limited", i+1)
+		}
+	}
+	ok, wait := l.Allow("k")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected limit with 500ms wait, got %v %v", ok, wait)
+	}
+	if ok, _ := l.Allow("other"); !ok {
+		t.Fatal("buckets must be independent")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("k"); !ok {
+		t.Fatal("token should have been refilled")
+	}
+}
+
+func TestAPIKeys_Middleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte("keys:\n  - name: coach-app\n    key: s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fileKeys, err := NewFileKeys(path)
+	if err != nil {
+		t.Fatalf("NewFileKeys: %v", err)
+	}
+	keys := NewAPIKeys(APIKeyConfig{
+		Keys:     KeyChain{fileKeys},
+		Required: true,
+		Public:   []string{"/healthz"},
+		PerKey:   NewRateLimiter(0.001, 2),
+		PerIP:    NewRateLimiter(0.001, 5),
+		Logger:   jsonlog.New(io.Discard, jsonlog.LevelOff),
+	})
+	h := keys.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	do := func(target, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("/exercises/chest", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("missing key: got %d", rec.Code)
+	}
+	if rec := do("/exercises/chest", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("invalid key: got %d", rec.Code)
+	}
+	if rec := do("/healthz", "wrong"); rec.Code != http.StatusNoContent {
+		t.Fatalf("public path: got %d", rec.Code)
+	}
+	for i := 0; i < 2; i++ {
+		if rec := do("/exercises/chest", "s3cret"); rec.Code != http.StatusNoContent {
+			t.Fatalf("valid key request %d: got %d", i+1, rec.Code)
+		}
+	}
+	rec := do("/exercises/chest", "s3cret")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	usage := keys.Usage()
+	if len(usage) != 3 || usage[0].Key != "coach-app" || usage[0].Requests != 3 || usage[0].Limited != 1 {
+		t.Fatalf("usage: %+v", usage)
+	}
+	for _, u := range usage[1:] {
+		if (u.Key == invalidKey || u.Key == anonymousKey) && u.Rejected != 1 {
+			t.Fatalf("expected one rejected request in %+v", u)
+		}
+	}
+}
+
+// countingKeys rejects every key and counts the lookups.
+type countingKeys struct{ lookups atomic.Int32 }
+
+func (k *countingKeys) ValidateKey(context.Context, string) (string, bool, error) {
+	k.lookups.Add(1)
+	return "", false, nil
+}
+
+func TestAPIKeys_LimitsBadKeysPerIP(t *testing.T) {
+	validator := &countingKeys{}
+	keys := NewAPIKeys(APIKeyConfig{
+		Keys:     validator,
+		Required: true,
+		PerIP:    NewRateLimiter(0.001, 3),
+		Logger:   jsonlog.New(io.Discard, jsonlog.LevelOff),
+	})
+	h := keys.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	var codes []int
+	for i := 0; i < 6; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/exercises/chest", nil)
+		if i%2 == 0 {
+			req.Header.Set("Authorization", "Bearer guess-"+strconv.Itoa(i))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	want := []int{401, 401, 401, 429, 429, 429}
+	if !slices.Equal(codes, want) {
+		t.Fatalf("got %v, want %v", codes, want)
+	}
+	if n := validator.lookups.Load(); n != 2 {
+		t.Fatalf("throttled requests must not reach the key store, got %d lookups", n)
+	}
+	var limited, rejected uint64
+	for _, u := range keys.Usage() {
+		limited += u.Limited
+		rejected += u.Rejected
+	}
+	if limited != 3 || rejected != 3 {
+		t.Fatalf("expected 3 limited and 3 rejected requests, got %d and %d", limited, rejected)
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"io"
+	"net"
+	"net/http"
+)
+
+// pageIterator walks a wger list endpoint page by page, following `next` links
+// until there are no more pages, the client's page cap is hit or ctx is done.
+type pageIterator[T any] struct {
+	c       *WgerClient
+	next    string
+	read    int
+	count   int
+	results []T
+	err     error
+}
+
+// pages iterates the legacy /exercise/ endpoint.
+func (c *WgerClient) pages(firstURL string) *pageIterator[wgerExercise] {
+	return pagesOf[wgerExercise](c, firstURL)
+}
+
+// pagesOf iterates any wger list endpoint whose results decode into T.
+func pagesOf[T any](c *WgerClient, firstURL string) *pageIterator[T] {
+	return &pageIterator[T]{c: c, next: firstURL}
+}
+
+// Next fetches the following page and reports whether one was read.
+func (it *pageIterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil || it.next == "" || it.read >= it.c.maxPages {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	var pr wgerPage[T]
+	if err := it.c.getJSON(ctx, it.next, &pr); err != nil {
+		it.err = err
+		return false
+	}
+	it.read++
+	it.count = pr.Count
+	it.results = pr.Results
+	it.next = ""
+	if pr.Next != nil {
+		it.next = *pr.Next
+	}
+	return true
+}
+
+// Results returns the items of the current page.
+func (it *pageIterator[T]) Results() []T {
+	return it.results
+}
+
+// Count is the total number of matches reported by wger.
+func (it *pageIterator[T]) Count() int {
+	return it.count
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *pageIterator[T]) Err() error {
+	return it.err
+}
+
+// getJSON performs a GET against wger and decodes the JSON body into dst.
+func (c *WgerClient) getJSON(ctx context.Context, u string, dst any) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return classifyTransportErr(err)
+	}
+	defer func(Body io.ReadCloser) {
+		if closeErr := Body.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &models.UpstreamError{StatusCode: resp.StatusCode}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrUpstreamBadResponse, err)
+	}
+	return nil
+}
+
+// classifyTransportErr maps a failed round trip onto the domain errors. Cancellation by the caller
+// is passed through untouched.
+func classifyTransportErr(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %v", models.ErrUpstreamTimeout, err)
+	default:
+		return fmt.Errorf("%w: %v", models.ErrUpstreamUnavailable, err)
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// pagedServer serves `total` exercises for each muscle leg in pages of `size`, linking pages via `next`.
+func pagedServer(t *testing.T, total, size int) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		base := 0
+		if r.URL.Query().Get("muscles_secondary") != "" {
+			base = 1000
+		}
+		pr := wgerPagedResponse{Count: total}
+		for i := offset; i < total && i < offset+size; i++ {
+			pr.Results = append(pr.Results, wgerExercise{ID: base + i + 1})
+		}
+		if offset+size < total {
+			q := r.URL.Query()
+			q.Set("offset", strconv.Itoa(offset+size))
+			next := srv.URL + r.URL.Path + "?" + q.Encode()
+			pr.Next = &next
+		}
+		_ = json.NewEncoder(w).Encode(pr)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchExercises_FollowsNextUpToCap(t *testing.T) {
+	srv := pagedServer(t, 7, 2)
+
+	single := NewWgerClient(srv.Client(), srv.URL, 2, "test")
+	got, err := single.FetchExercises(context.Background(), []int{12}, 2, ExerciseFilter{})
+	if err != nil {
+		t.Fatalf("FetchExercises: %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("single-page mode: expected 2 primary + 2 secondary, got %d", len(got))
+	}
+
+	paged := NewWgerClient(srv.Client(), srv.URL, 2, "test").WithMaxPages(10)
+	got, err = paged.FetchExercises(context.Background(), []int{12}, 2, ExerciseFilter{})
+	if err != nil {
+		t.Fatalf("FetchExercises: %v", err)
+	}
+	if len(got) != 14 {
+		t.Fatalf("paginated mode: expected all 7+7 exercises, got %d", len(got))
+	}
+
+	capped := NewWgerClient(srv.Client(), srv.URL, 2, "test").WithMaxPages(2)
+	got, err = capped.FetchExercises(context.Background(), []int{12}, 2, ExerciseFilter{})
+	if err != nil {
+		t.Fatalf("FetchExercises: %v", err)
+	}
+	if len(got) != 8 {
+		t.Fatalf("capped mode: expected 2 pages per leg (8), got %d", len(got))
+	}
+}
+
+func TestPageIterator_StopsOnCancelledContext(t *testing.T) {
+	srv := pagedServer(t, 10, 2)
+	c := NewWgerClient(srv.Client(), srv.URL, 2, "test").WithMaxPages(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := c.pages(srv.URL + "/exercise/?muscles=1&limit=2")
+	if !it.Next(ctx) {
+		t.Fatalf("expected first page, err=%v", it.Err())
+	}
+	cancel()
+	if it.Next(ctx) {
+		t.Fatalf("expected iteration to stop after cancel")
+	}
+	if it.Err() != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", it.Err())
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetFilter narrows a user's set history. Zero values don't filter; MuscleIDs matches sets whose
+// exercise primarily works any of them (only exercises in the local mirror can match).
+type SetFilter struct {
+	ExerciseID int
+	MuscleIDs  []int
+	From, To   time.Time
+	Limit      int
+}
+
+// LogSet stores a completed set and returns the exercise's sets logged before it (weight, reps and
+// RPE only). The insert comes first so concurrent logs queue on the write lock and each sees the
+// others' sets.
+func (s *UserStore) LogSet(ctx context.Context, userID int64, set models.SetLog) (_ models.SetLog, prev []models.SetLog, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.SetLog{}, nil, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	// timestamps are stored in seconds, like every other table
+	set.PerformedAt = set.PerformedAt.Truncate(time.Second)
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO set_logs (user_id, exercise_id, weight, reps, rpe, performed_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, set.ExerciseID, set.Weight, set.Reps, set.RPE, set.PerformedAt.Unix())
+	if err != nil {
+		return models.SetLog{}, nil, err
+	}
+	if set.ID, err = res.LastInsertId(); err != nil {
+		return models.SetLog{}, nil, err
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT weight, reps, rpe FROM set_logs WHERE user_id = ? AND exercise_id = ? AND id <> ?`,
+		userID, set.ExerciseID, set.ID)
+	if err != nil {
+		return models.SetLog{}, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		p := models.SetLog{ExerciseID: set.ExerciseID}
+		var rpe sql.NullFloat64
+		if err = rows.Scan(&p.Weight, &p.Reps, &rpe); err != nil {
+			return models.SetLog{}, nil, err
+		}
+		if rpe.Valid {
+			p.RPE = &rpe.Float64
+		}
+		prev = append(prev, p)
+	}
+	if err = rows.Err(); err != nil {
+		return models.SetLog{}, nil, err
+	}
+	return set, prev, tx.Commit()
+}
+
+// DeleteSet removes one of the user's sets.
+func (s *UserStore) DeleteSet(ctx context.Context, userID, id int64) error {
+	return execOne(s.db.ExecContext(ctx, `DELETE FROM set_logs WHERE id = ? AND user_id = ?`, id, userID))
+}
+
+// Sets returns the user's sets matching f, newest first, with exercise names and primary muscles
+// from the mirror when known.
+func (s *UserStore) Sets(ctx context.Context, userID int64, f SetFilter) ([]models.SetLog, error) {
+	var sb strings.Builder
+	args := []any{userID}
+	sb.WriteString(`
+		SELECT s.id, s.exercise_id, COALESCE(e.name, ''), s.weight, s.reps, s.rpe, s.performed_at,
+			COALESCE((SELECT group_concat(m.muscle_id) FROM exercise_muscles m
+				WHERE m.exercise_id = s.exercise_id AND m.is_primary = 1), '')
+		FROM set_logs s LEFT JOIN exercises e ON e.id = s.exercise_id
+		WHERE s.user_id = ?`)
+	if f.ExerciseID != 0 {
+		sb.WriteString(` AND s.exercise_id = ?`)
+		args = append(args, f.ExerciseID)
+	}
+	if !f.From.IsZero() {
+		sb.WriteString(` AND s.performed_at >= ?`)
+		args = append(args, f.From.Unix())
+	}
+	if !f.To.IsZero() {
+		sb.WriteString(` AND s.performed_at < ?`)
+		args = append(args, f.To.Unix())
+	}
+	if len(f.MuscleIDs) > 0 {
+		sb.WriteString(` AND EXISTS (SELECT 1 FROM exercise_muscles m WHERE m.exercise_id = s.exercise_id
+			AND m.is_primary = 1 AND m.muscle_id IN (` + placeholders(len(f.MuscleIDs)) + `))`)
+		for _, id := range f.MuscleIDs {
+			args = append(args, id)
+		}
+	}
+	sb.WriteString(` ORDER BY s.performed_at DESC, s.id DESC`)
+	if f.Limit > 0 {
+		sb.WriteString(` LIMIT ?`)
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []models.SetLog{}
+	for rows.Next() {
+		var set models.SetLog
+		var rpe sql.NullFloat64
+		var performed int64
+		var muscles string
+		if err := rows.Scan(&set.ID, &set.ExerciseID, &set.ExerciseName, &set.Weight, &set.Reps, &rpe, &performed, &muscles); err != nil {
+			return nil, err
+		}
+		if rpe.Valid {
+			set.RPE = &rpe.Float64
+		}
+		set.PerformedAt = time.Unix(performed, 0).UTC()
+		for _, part := range strings.Split(muscles, ",") {
+			if id, err := strconv.Atoi(part); err == nil {
+				set.Muscles = append(set.Muscles, id)
+			}
+		}
+		out = append(out, set)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TransportConfig tunes Transport. Zero values pick the defaults noted on each field.
+type TransportConfig struct {
+	// RateLimit caps outgoing requests per second (<= 0: unlimited); Burst defaults to 1.
+	RateLimit float64
+	Burst     int
+	// MaxRetries is how often a 429/5xx response or network error is retried (0: never).
+	MaxRetries int
+	// BaseBackoff and MaxBackoff bound the jittered exponential backoff (default 200ms and 5s).
+	// A Retry-After longer than MaxBackoff is not waited for.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// FailureThreshold consecutive failures open the circuit (default 5) for OpenTimeout (default 30s).
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// Transport wraps an http.RoundTripper with an outbound rate limit, retries with jittered
+// exponential backoff (honouring Retry-After) and a circuit breaker. Only GET and HEAD are retried.
+type Transport struct {
+	next    http.RoundTripper
+	cfg     TransportConfig
+	limiter *RateLimiter
+	breaker *circuitBreaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func NewTransport(next http.RoundTripper, cfg TransportConfig) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 200 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Second
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	return &Transport{
+		next:    next,
+		cfg:     cfg,
+		limiter: NewRateLimiter(cfg.RateLimit, cfg.Burst),
+		breaker: &circuitBreaker{threshold: cfg.FailureThreshold, openFor: cfg.OpenTimeout, now: time.Now},
+		sleep:   sleepCtx,
+	}
+}
+
+// Circuit reports the circuit breaker state.
+func (t *Transport) Circuit() models.CircuitStatus {
+	return t.breaker.status()
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retries := max(t.cfg.MaxRetries, 0)
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		retries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		if !t.breaker.allow() {
+			return nil, models.ErrCircuitOpen
+		}
+		if err := t.limiter.Wait(ctx, "wger"); err != nil {
+			t.breaker.cancel()
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		// a caller hanging up says nothing about wger, but a deadline (http.Client.Timeout or a
+		// context timeout) running out while waiting for it does
+		canceled := errors.Is(ctx.Err(), context.Canceled)
+		failed := !canceled && (err != nil || resp.StatusCode >= 500)
+		switch {
+		case canceled:
+			t.breaker.cancel()
+		case failed:
+			t.breaker.failure()
+		default:
+			t.breaker.success()
+		}
+
+		retryable := failed || err == nil && resp.StatusCode == http.StatusTooManyRequests
+		if !retryable || attempt >= retries {
+			return resp, err
+		}
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if ra, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				if ra > t.cfg.MaxBackoff {
+					return resp, nil
+				}
+				delay = ra
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff is "full jitter": a random delay up to BaseBackoff*2^attempt, capped at MaxBackoff.
+func (t *Transport) backoff(attempt int) time.Duration {
+	ceiling := min(t.cfg.BaseBackoff<<attempt, t.cfg.MaxBackoff)
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half_open"
+)
+
+// circuitBreaker opens after threshold consecutive failures, fails fast for openFor, then lets a
+// single probe through: its success closes the circuit, its failure opens it again.
+type circuitBreaker struct {
+	threshold int
+	openFor   time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.openFor {
+			return false
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		return true
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = circuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// cancel releases a probe slot when the caller gave up before an outcome was known.
+func (b *circuitBreaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) status() models.CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := models.CircuitStatus{State: b.state, ConsecutiveFailures: b.failures}
+	if st.State == "" {
+		st.State = circuitClosed
+	}
+	if b.state != circuitClosed && b.state != "" {
+		opened := b.openedAt.UTC()
+		retry := opened.Add(b.openFor)
+		st.OpenedAt, st.RetryAt = &opened, &retry
+	}
+	return st
+}
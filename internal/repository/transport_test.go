@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransport_RetriesHonouringRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	tr := NewTransport(nil, TransportConfig{MaxRetries: 2})
+	var slept []time.Duration
+	tr.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("expected success on the third call, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+	if len(slept) != 2 || slept[0] != time.Second || slept[1] > 400*time.Millisecond {
+		t.Fatalf("unexpected backoff %v", slept)
+	}
+	if st := tr.Circuit(); st.State != "closed" || st.ConsecutiveFailures != 0 {
+		t.Fatalf("circuit should be closed after a success, got %+v", st)
+	}
+}
+
+func TestTransport_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	healthy := atomic.Bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1000, 0)
+	tr := NewTransport(nil, TransportConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	tr.breaker.now = func() time.Time { return now }
+	client := &http.Client{Transport: tr}
+	get := func() error {
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if st := tr.Circuit(); st.State != "open" || st.RetryAt == nil {
+		t.Fatalf("expected an open circuit, got %+v", st)
+	}
+	if err := get(); !errors.Is(err, models.ErrCircuitOpen) || !errors.Is(err, models.ErrUpstreamUnavailable) {
+		t.Fatalf("expected a fast failure, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("open circuit must not call upstream, got %d calls", calls.Load())
+	}
+
+	// after the cooldown a failing probe re-opens the circuit, a successful one closes it
+	now = now.Add(time.Minute)
+	_ = get()
+	if st := tr.Circuit(); st.State != "open" {
+		t.Fatalf("failed probe should re-open the circuit, got %+v", st)
+	}
+	now = now.Add(time.Minute)
+	healthy.Store(true)
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+	if st := tr.Circuit(); st.State != "closed" {
+		t.Fatalf("successful probe should close the circuit, got %+v", st)
+	}
+}
+
+func TestTransport_TimeoutsOpenTheCircuit(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	tr := NewTransport(nil, TransportConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	client := &http.Client{Transport: tr, Timeout: 20 * time.Millisecond}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(srv.URL); err == nil {
+			t.Fatal("expected a timeout")
+		}
+	}
+	if st := tr.Circuit(); st.State != "open" {
+		t.Fatalf("timeouts should open the circuit, got %+v", st)
+	}
+
+	// a caller giving up is not held against wger
+	tr = NewTransport(nil, TransportConfig{FailureThreshold: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := (&http.Client{Transport: tr}).Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if st := tr.Circuit(); st.State != "closed" || st.ConsecutiveFailures != 0 {
+		t.Fatalf("cancellation must not count as a failure, got %+v", st)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d, ok := retryAfter("3"); !ok || d != 3*time.Second {
+		t.Fatalf("seconds: got %v %v", d, ok)
+	}
+	if d, ok := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || d < 59*time.Minute {
+		t.Fatalf("http date: got %v %v", d, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Fatal("garbage must be ignored")
+	}
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+	"time"
+)
+
+// UserStore keeps accounts, API tokens, saved workouts and favourites in the same SQLite
+// database as the exercise mirror.
+type UserStore struct {
+	db *sql.DB
+}
+
+const usersSchema = `
+CREATE TABLE IF NOT EXISTS users (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	email         TEXT    NOT NULL UNIQUE COLLATE NOCASE,
+	password_hash TEXT    NOT NULL,
+	created_at    INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS api_tokens (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id      INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	token_hash   TEXT    NOT NULL UNIQUE,
+	name         TEXT    NOT NULL DEFAULT '',
+	created_at   INTEGER NOT NULL,
+	last_used_at INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens(user_id);
+CREATE TABLE IF NOT EXISTS saved_workouts (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name       TEXT    NOT NULL,
+	body       TEXT    NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_saved_workouts_user ON saved_workouts(user_id);
+CREATE TABLE IF NOT EXISTS favorites (
+	user_id     INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	exercise_id INTEGER NOT NULL,
+	created_at  INTEGER NOT NULL,
+	PRIMARY KEY (user_id, exercise_id)
+);
+CREATE TABLE IF NOT EXISTS set_logs (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id      INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	exercise_id  INTEGER NOT NULL,
+	weight       REAL    NOT NULL,
+	reps         INTEGER NOT NULL,
+	rpe          REAL,
+	performed_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_set_logs_user_time ON set_logs(user_id, performed_at);
+CREATE INDEX IF NOT EXISTS idx_set_logs_user_exercise ON set_logs(user_id, exercise_id, performed_at);
+`
+
+// NewUserStore creates the user tables if needed. It shares db with ExerciseStore; the catalog
+// tables are created too since favourites are joined with the exercise mirror.
+func NewUserStore(ctx context.Context, db *sql.DB) (*UserStore, error) {
+	if db == nil {
+		return nil, errors.New("nil database")
+	}
+	if _, err := db.ExecContext(ctx, catalogSchema+usersSchema); err != nil {
+		return nil, err
+	}
+	return &UserStore{db: db}, nil
+}
+
+// CreateUser inserts a user; a duplicate email yields models.ErrEmailTaken.
+func (s *UserStore) CreateUser(ctx context.Context, email, passwordHash string) (models.User, error) {
+	now := time.Now()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO users (email, password_hash, created_at) VALUES (?, ?, ?)`,
+		email, passwordHash, now.Unix())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.User{}, models.ErrEmailTaken
+		}
+		return models.User{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.User{}, err
+	}
+	return models.User{ID: id, Email: email, CreatedAt: time.Unix(now.Unix(), 0)}, nil
+}
+
+// UserByEmail returns the user and its password hash, or models.ErrNotFound.
+func (s *UserStore) UserByEmail(ctx context.Context, email string) (models.User, string, error) {
+	var u models.User
+	var hash string
+	var created int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, email, password_hash, created_at FROM users WHERE email = ?`, email).
+		Scan(&u.ID, &u.Email, &hash, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, "", models.ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, "", err
+	}
+	u.CreatedAt = time.Unix(created, 0)
+	return u, hash, nil
+}
+
+// CreateToken stores the hash of a newly issued token.
+func (s *UserStore) CreateToken(ctx context.Context, userID int64, name, tokenHash string) (models.APIToken, error) {
+	now := time.Now().Unix()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO api_tokens (user_id, token_hash, name, created_at) VALUES (?, ?, ?, ?)`,
+		userID, tokenHash, name, now)
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	return models.APIToken{ID: id, Name: name, CreatedAt: time.Unix(now, 0)}, nil
+}
+
+// tokenUseResolution bounds how often a token's last_used_at is rewritten, so authenticated reads
+// don't each cost a write.
+const tokenUseResolution = time.Minute
+
+// UserByToken resolves a token hash to its user and records the use; unknown hashes yield models.ErrNotFound.
+func (s *UserStore) UserByToken(ctx context.Context, tokenHash string) (models.User, error) {
+	var u models.User
+	var created int64
+	var used sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT u.id, u.email, u.created_at, t.last_used_at
+		FROM api_tokens t JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = ?`, tokenHash).Scan(&u.ID, &u.Email, &created, &used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, models.ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	u.CreatedAt = time.Unix(created, 0)
+	now := time.Now()
+	if used.Valid && now.Sub(time.Unix(used.Int64, 0)) < tokenUseResolution {
+		return u, nil
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE api_tokens SET last_used_at = ? WHERE token_hash = ?`, now.Unix(), tokenHash); err != nil {
+		return models.User{}, err
+	}
+	return u, nil
+}
+
+// Tokens lists a user's tokens without their secrets.
+func (s *UserStore) Tokens(ctx context.Context, userID int64) ([]models.APIToken, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, created_at, last_used_at FROM api_tokens WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []models.APIToken{}
+	for rows.Next() {
+		var t models.APIToken
+		var created int64
+		var used sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.Name, &created, &used); err != nil {
+			return nil, err
+		}
+		t.CreatedAt = time.Unix(created, 0)
+		if used.Valid {
+			at := time.Unix(used.Int64, 0)
+			t.LastUsedAt = &at
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// DeleteToken revokes one of the user's tokens.
+func (s *UserStore) DeleteToken(ctx context.Context, userID, tokenID int64) error {
+	return execOne(s.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE id = ? AND user_id = ?`, tokenID, userID))
+}
+
+// SaveWorkout inserts a workout for the user.
+func (s *UserStore) SaveWorkout(ctx context.Context, userID int64, name string, w models.Workout) (models.SavedWorkout, error) {
+	body, err := json.Marshal(w)
+	if err != nil {
+		return models.SavedWorkout{}, err
+	}
+	now := time.Now().Unix()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO saved_workouts (user_id, name, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		userID, name, string(body), now, now)
+	if err != nil {
+		return models.SavedWorkout{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.SavedWorkout{}, err
+	}
+	return models.SavedWorkout{ID: id, Name: name, Workout: w, CreatedAt: time.Unix(now, 0), UpdatedAt: time.Unix(now, 0)}, nil
+}
+
+// UpdateWorkout replaces a saved workout's name and body.
+func (s *UserStore) UpdateWorkout(ctx context.Context, userID, id int64, name string, w models.Workout) error {
+	body, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	return execOne(s.db.ExecContext(ctx,
+		`UPDATE saved_workouts SET name = ?, body = ?, updated_at = ? WHERE id = ? AND user_id = ?`,
+		name, string(body), time.Now().Unix(), id, userID))
+}
+
+// DeleteWorkout removes a saved workout.
+func (s *UserStore) DeleteWorkout(ctx context.Context, userID, id int64) error {
+	return execOne(s.db.ExecContext(ctx, `DELETE FROM saved_workouts WHERE id = ? AND user_id = ?`, id, userID))
+}
+
+// Workout returns one saved workout or models.ErrNotFound.
+func (s *UserStore) Workout(ctx context.Context, userID, id int64) (models.SavedWorkout, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, body, created_at, updated_at FROM saved_workouts
+		WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return models.SavedWorkout{}, err
+	}
+	list, err := scanWorkouts(rows)
+	if err != nil {
+		return models.SavedWorkout{}, err
+	}
+	if len(list) == 0 {
+		return models.SavedWorkout{}, models.ErrNotFound
+	}
+	return list[0], nil
+}
+
+// Workouts lists the user's saved workouts, newest first.
+func (s *UserStore) Workouts(ctx context.Context, userID int64) ([]models.SavedWorkout, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, body, created_at, updated_at FROM saved_workouts
+		WHERE user_id = ? ORDER BY updated_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWorkouts(rows)
+}
+
+func scanWorkouts(rows *sql.Rows) ([]models.SavedWorkout, error) {
+	defer func() { _ = rows.Close() }()
+	out := []models.SavedWorkout{}
+	for rows.Next() {
+		var w models.SavedWorkout
+		var body string
+		var created, updated int64
+		if err := rows.Scan(&w.ID, &w.Name, &body, &created, &updated); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(body), &w.Workout); err != nil {
+			return nil, err
+		}
+		w.CreatedAt, w.UpdatedAt = time.Unix(created, 0), time.Unix(updated, 0)
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// AddFavorite bookmarks an exercise; adding it again is a no-op.
+func (s *UserStore) AddFavorite(ctx context.Context, userID int64, exerciseID int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO favorites (user_id, exercise_id, created_at) VALUES (?, ?, ?)
+		ON CONFLICT(user_id, exercise_id) DO NOTHING`, userID, exerciseID, time.Now().Unix())
+	return err
+}
+
+// RemoveFavorite drops a bookmark.
+func (s *UserStore) RemoveFavorite(ctx context.Context, userID int64, exerciseID int) error {
+	return execOne(s.db.ExecContext(ctx, `DELETE FROM favorites WHERE user_id = ? AND exercise_id = ?`, userID, exerciseID))
+}
+
+// Favorites lists bookmarks, newest first, with names from the exercise mirror when available.
+func (s *UserStore) Favorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT f.exercise_id, COALESCE(e.name, ''), f.created_at
+		FROM favorites f LEFT JOIN exercises e ON e.id = f.exercise_id
+		WHERE f.user_id = ? ORDER BY f.created_at DESC, f.exercise_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []models.Favorite{}
+	for rows.Next() {
+		var f models.Favorite
+		var created int64
+		if err := rows.Scan(&f.ExerciseID, &f.Name, &created); err != nil {
+			return nil, err
+		}
+		f.CreatedAt = time.Unix(created, 0)
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// execOne turns "no rows affected" into models.ErrNotFound.
+func execOne(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
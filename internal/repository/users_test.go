@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUserStore_OwnershipAndCascade(t *testing.T) {
+	ctx := context.Background()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	exercises, err := NewExerciseStore(ctx, db)
+	if err != nil {
+		t.Fatalf("NewExerciseStore: %v", err)
+	}
+	store, err := NewUserStore(ctx, db)
+	if err != nil {
+		t.Fatalf("NewUserStore: %v", err)
+	}
+
+	alice, err := store.CreateUser(ctx, "alice@example.com", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := store.CreateUser(ctx, "ALICE@example.com", "hash"); !errors.Is(err, models.ErrEmailTaken) {
+		t.Fatalf("duplicate email: got %v", err)
+	}
+	bob, _ := store.CreateUser(ctx, "bob@example.com", "hash")
+
+	saved, err := store.SaveWorkout(ctx, alice.ID, "push day", models.Workout{Muscles: []string{"chest"}, Level: "beginner"})
+	if err != nil {
+		t.Fatalf("SaveWorkout: %v", err)
+	}
+	if _, err := store.Workout(ctx, bob.ID, saved.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("other user's workout: got %v", err)
+	}
+	if err := store.DeleteWorkout(ctx, bob.ID, saved.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("deleting other user's workout: got %v", err)
+	}
+	got, err := store.Workout(ctx, alice.ID, saved.ID)
+	if err != nil || got.Name != "push day" || got.Workout.Muscles[0] != "chest" {
+		t.Fatalf("Workout: %+v %v", got, err)
+	}
+
+	if err := exercises.UpsertExercises(ctx, []models.Exercise{{ID: 9, Name: "Bench Press", Muscles: []int{4}}}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := store.AddFavorite(ctx, alice.ID, 9); err != nil {
+			t.Fatalf("AddFavorite: %v", err)
+		}
+	}
+	_ = store.AddFavorite(ctx, alice.ID, 12)
+	favs, err := store.Favorites(ctx, alice.ID)
+	if err != nil || len(favs) != 2 {
+		t.Fatalf("Favorites: %+v %v", favs, err)
+	}
+	for _, f := range favs {
+		if f.ExerciseID == 9 && f.Name != "Bench Press" {
+			t.Fatalf("favourite name not joined: %+v", f)
+		}
+	}
+
+	if _, err := store.CreateToken(ctx, alice.ID, "cli", "deadbeef"); err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+	if u, err := store.UserByToken(ctx, "deadbeef"); err != nil || u.ID != alice.ID {
+		t.Fatalf("UserByToken: %+v %v", u, err)
+	}
+	tokens, _ := store.Tokens(ctx, alice.ID)
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Fatalf("token use not recorded: %+v", tokens)
+	}
+	// recent uses are not rewritten; older ones are
+	lastUsed := func() int64 {
+		var v int64
+		_ = db.QueryRowContext(ctx, `SELECT last_used_at FROM api_tokens WHERE token_hash = 'deadbeef'`).Scan(&v)
+		return v
+	}
+	recent := time.Now().Add(-10 * time.Second).Unix()
+	_, _ = db.ExecContext(ctx, `UPDATE api_tokens SET last_used_at = ?`, recent)
+	_, _ = store.UserByToken(ctx, "deadbeef")
+	if lastUsed() != recent {
+		t.Fatal("a use within tokenUseResolution must not write")
+	}
+	_, _ = db.ExecContext(ctx, `UPDATE api_tokens SET last_used_at = ?`, recent-120)
+	_, _ = store.UserByToken(ctx, "deadbeef")
+	if lastUsed() <= recent {
+		t.Fatal("a stale last_used_at should be refreshed")
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.UserByToken(ctx, "deadbeef"); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("token should be gone with its user: %v", err)
+	}
+}
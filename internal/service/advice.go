@@ -1,41 +1,224 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/cache"
 	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"gopkg.in/yaml.v3"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
-const adviceURL = "https://api.adviceslip.com/advice"
+// AdviceSlipURL is the public adviceslip.com endpoint.
+const AdviceSlipURL = "https://api.adviceslip.com/advice"
 
-var httpClientAdvice = &http.Client{Timeout: 10 * time.Second}
+// NoAdvice is what clients get when no provider could answer.
+const NoAdvice = "no advice for today"
 
-const fail = "no advice for today"
+// ErrNoAdvice is returned by providers that have nothing for the requested muscle.
+var ErrNoAdvice = errors.New("no advice available")
 
-func GetAdvice() string {
-	resp, err := httpClientAdvice.Get(adviceURL)
+// AdviceProvider returns a short piece of advice. An empty muscle asks for general advice;
+// otherwise the advice must be specific to that muscle.
+type AdviceProvider interface {
+	Advice(ctx context.Context, muscle string) (string, error)
+}
+
+// TipLister is implemented by providers that can list every tip they have for a muscle, so the
+// list can be cached while a different tip is still picked per request.
+type TipLister interface {
+	Tips(ctx context.Context, muscle string) ([]string, error)
+}
+
+// tipsOf lists p's tips for muscle; providers that can't list answer with a single tip.
+func tipsOf(ctx context.Context, p AdviceProvider, muscle string) ([]string, error) {
+	if l, ok := p.(TipLister); ok {
+		return l.Tips(ctx, muscle)
+	}
+	advice, err := p.Advice(ctx, muscle)
+	if err != nil {
+		return nil, err
+	}
+	return []string{advice}, nil
+}
+
+func pickTip(list []string) string {
+	return list[rand.IntN(len(list))]
+}
+
+// SlipProvider fetches random advice from adviceslip.com (or a compatible endpoint).
+type SlipProvider struct {
+	client *http.Client
+	url    string
+}
+
+func NewSlipProvider(client *http.Client, url string) *SlipProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if url == "" {
+		url = AdviceSlipURL
+	}
+	return &SlipProvider{client: client, url: url}
+}
+
+// Advice only answers general requests: adviceslip knows nothing about muscles.
+func (p *SlipProvider) Advice(ctx context.Context, muscle string) (advice string, err error) {
+	if muscle != "" {
+		return "", ErrNoAdvice
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
 	if err != nil {
-		return fail
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
 	}
 	defer func(Body io.ReadCloser) {
-		if err := Body.Close(); err != nil {
-			return
+		if closeErr := Body.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
 		}
 	}(resp.Body)
-	
+
 	if resp.StatusCode != http.StatusOK {
-		return fail
+		return "", fmt.Errorf("adviceslip returned %d", resp.StatusCode)
 	}
-	
+
 	var data models.AdviceResponse
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return fail
+		return "", err
 	}
 	if data.Slip.Advice == "" {
-		return fail
+		return "", errors.New("adviceslip returned empty advice")
+	}
+	return data.Slip.Advice, nil
+}
+
+// tipsFile is the layout of the curated tips file (JSON or YAML).
+type tipsFile struct {
+	General []string            `json:"general" yaml:"general"`
+	Muscles map[string][]string `json:"muscles" yaml:"muscles"`
+}
+
+// FileProvider serves random tips from a curated local JSON or YAML file.
+type FileProvider struct {
+	tips tipsFile
+}
+
+// NewFileProvider loads tips from path; the format is picked by extension (.json, .yaml/.yml).
+func NewFileProvider(path string) (*FileProvider, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var tips tipsFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(b, &tips)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &tips)
+	default:
+		return nil, fmt.Errorf("unsupported tips file format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	muscles := make(map[string][]string, len(tips.Muscles))
+	for name, list := range tips.Muscles {
+		muscles[normalizeMuscle(name)] = list
+	}
+	tips.Muscles = muscles
+	return &FileProvider{tips: tips}, nil
+}
+
+func (p *FileProvider) Advice(ctx context.Context, muscle string) (string, error) {
+	list, err := p.Tips(ctx, muscle)
+	if err != nil {
+		return "", err
+	}
+	return pickTip(list), nil
+}
+
+func (p *FileProvider) Tips(_ context.Context, muscle string) ([]string, error) {
+	list := p.tips.General
+	if muscle != "" {
+		list = p.tips.Muscles[normalizeMuscle(muscle)]
+	}
+	if len(list) == 0 {
+		return nil, ErrNoAdvice
+	}
+	return list, nil
+}
+
+// ChainProvider asks each provider in turn and returns the first answer.
+type ChainProvider []AdviceProvider
+
+func (c ChainProvider) Advice(ctx context.Context, muscle string) (string, error) {
+	list, err := c.Tips(ctx, muscle)
+	if err != nil {
+		return "", err
+	}
+	return pickTip(list), nil
+}
+
+// Tips returns the tips of the first provider that has any.
+func (c ChainProvider) Tips(ctx context.Context, muscle string) ([]string, error) {
+	var errs []error
+	for _, p := range c {
+		list, err := tipsOf(ctx, p, muscle)
+		if err == nil {
+			return list, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no advice providers configured")
+	}
+	return nil, errors.Join(errs...)
+}
+
+// CachedProvider remembers the tips per muscle for a short TTL so repeated hits don't reach upstream,
+// and picks a random one per request. Failures are not cached.
+type CachedProvider struct {
+	next  AdviceProvider
+	cache *cache.LRU[[]string]
+}
+
+func NewCachedProvider(next AdviceProvider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{next: next, cache: cache.NewLRU[[]string](256, ttl)}
+}
+
+func (p *CachedProvider) Advice(ctx context.Context, muscle string) (string, error) {
+	list, err := p.Tips(ctx, muscle)
+	if err != nil {
+		return "", err
+	}
+	return pickTip(list), nil
+}
+
+func (p *CachedProvider) Tips(ctx context.Context, muscle string) ([]string, error) {
+	key := normalizeMuscle(muscle)
+	if list, _, ok := p.cache.Get(key); ok {
+		return list, nil
+	}
+	list, err := tipsOf(ctx, p.next, muscle)
+	if err != nil {
+		return nil, err
 	}
-	
-	return data.Slip.Advice
+	p.cache.Set(key, list)
+	return list, nil
 }
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/cache"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"strconv"
+	"time"
+)
+
+type cacheItem struct {
+	storedAt time.Time
+	data     []models.Exercise
+}
+
+// exerciseSet is a muscle's exercises together with how fresh they are.
+type exerciseSet struct {
+	data  []models.Exercise
+	age   time.Duration
+	stale bool
+	// partial is set when only the primary-muscle leg could be fetched; warnings say why.
+	partial  bool
+	warnings []string
+}
+
+// exercisesFor returns every known exercise for the muscle IDs, ordered by ID so offsets stay stable.
+// Results are narrowed by f on the wger side; the store fallback is unfiltered.
+//
+// Fresh entries are served as is. Entries past ttl are served stale while a background refresh runs,
+// and once past the revalidation window they are refetched, falling back to the stale copy (or the
+// store) when wger fails. Concurrent fetches for the same key are coalesced.
+func (s *FitnessService) exercisesFor(ctx context.Context, muscleKey string, ids []int, f repository.ExerciseFilter) (exerciseSet, error) {
+	cacheKey := cacheKeyFor(muscleKey, f)
+	item, cached := s.getCache(ctx, cacheKey)
+	if cached {
+		age := time.Since(item.storedAt)
+		switch {
+		case age < s.ttl:
+			return exerciseSet{data: item.data, age: age}, nil
+		case age < s.ttl+s.staleWhileRevalidate:
+			s.refreshAsync(cacheKey, muscleKey, ids, f)
+			return exerciseSet{data: item.data, age: age, stale: true}, nil
+		}
+	}
+
+	data, err := s.fetchShared(ctx, cacheKey, muscleKey, ids, f)
+	if err == nil {
+		return exerciseSet{data: data}, nil
+	}
+	var partial *models.PartialError
+	if errors.As(err, &partial) {
+		s.logger.PrintError("wger answered partially", map[string]string{
+			"muscle": muscleKey,
+			"error":  err.Error(),
+		})
+		// a complete stale copy beats a fresh half
+		if age := time.Since(item.storedAt); cached && age < s.ttl+s.staleIfError {
+			return exerciseSet{data: item.data, age: age, stale: true}, nil
+		}
+		return exerciseSet{data: data, partial: true, warnings: []string{
+			"exercises that work this muscle only secondarily are missing",
+		}}, nil
+	}
+
+	set, ok := s.offline(ctx, muscleKey, ids, f)
+	if !ok {
+		return exerciseSet{}, err
+	}
+	s.logger.PrintError("wger unavailable, serving stale exercises", map[string]string{
+		"muscle": muscleKey,
+		"age":    set.age.Round(time.Second).String(),
+		"error":  err.Error(),
+	})
+	return set, nil
+}
+
+// offline answers without calling wger: from the cache while within staleIfError, else from the
+// store, which mirrors the default language only.
+func (s *FitnessService) offline(ctx context.Context, muscleKey string, ids []int, f repository.ExerciseFilter) (exerciseSet, bool) {
+	if item, ok := s.getCache(ctx, cacheKeyFor(muscleKey, f)); ok {
+		if age := time.Since(item.storedAt); age < s.ttl+s.staleIfError {
+			return exerciseSet{data: item.data, age: age, stale: age >= s.ttl}, true
+		}
+	}
+	if f.Language != 0 {
+		return exerciseSet{}, false
+	}
+	stored, updatedAt, ok := s.fromStore(ctx, muscleKey, ids, 0)
+	return exerciseSet{data: stored, age: time.Since(updatedAt), stale: true}, ok
+}
+
+// fetchShared fetches from wger and refreshes the cache; identical concurrent fetches share one call.
+// Partial results come back with a *models.PartialError; they are stored but not cached, so the
+// next request tries wger again. Only the default language is mirrored into the store.
+func (s *FitnessService) fetchShared(ctx context.Context, cacheKey, muscleKey string, ids []int, f repository.ExerciseFilter) ([]models.Exercise, error) {
+	return s.flight.Do(ctx, cacheKey, func(ctx context.Context) ([]models.Exercise, error) {
+		data, err := s.client.FetchExercises(ctx, ids, fetchPageSize, f)
+		var partial *models.PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return nil, err
+		}
+		if f.Language == 0 {
+			s.persist(ctx, muscleKey, data)
+		}
+		if partial != nil {
+			return data, partial
+		}
+		s.setCache(ctx, cacheKey, data)
+		return data, nil
+	})
+}
+
+// refreshAsync revalidates a stale entry in the background.
+func (s *FitnessService) refreshAsync(cacheKey, muscleKey string, ids []int, f repository.ExerciseFilter) {
+	go func() {
+		if _, err := s.fetchShared(context.Background(), cacheKey, muscleKey, ids, f); err != nil {
+			s.logger.PrintError("background refresh failed", map[string]string{
+				"muscle": muscleKey,
+				"error":  err.Error(),
+			})
+		}
+	}()
+}
+
+// cacheKeyFor keys a muscle's exercises by language and filter. The server's default language keeps
+// the bare muscle name; every variant starts with "<muscle>|" so PurgeCache finds it.
+func cacheKeyFor(muscle string, f repository.ExerciseFilter) string {
+	key := muscle
+	if f.Language != 0 {
+		key += "|l=" + strconv.Itoa(f.Language)
+	}
+	if fk := filterKey(f); fk != "" {
+		key += "|" + fk
+	}
+	return key
+}
+
+// cacheMaxAge is how long an entry is worth keeping at all: past this it can't even serve as a fallback.
+func (s *FitnessService) cacheMaxAge() time.Duration {
+	return s.ttl + max(s.staleWhileRevalidate, s.staleIfError)
+}
+
+// getCache returns the entry for key. Backend failures are logged and treated as a miss.
+func (s *FitnessService) getCache(ctx context.Context, key string) (cacheItem, bool) {
+	data, storedAt, ok, err := s.cache.Get(ctx, key)
+	if err != nil {
+		s.logger.PrintError("cache read failed", map[string]string{"key": key, "error": err.Error()})
+		return cacheItem{}, false
+	}
+	if !ok || time.Since(storedAt) >= s.cacheMaxAge() {
+		return cacheItem{}, false
+	}
+	return cacheItem{storedAt: storedAt, data: data}, true
+}
+
+func (s *FitnessService) setCache(ctx context.Context, key string, data []models.Exercise) {
+	if err := s.cache.Set(ctx, key, data); err != nil {
+		s.logger.PrintError("cache write failed", map[string]string{"key": key, "error": err.Error()})
+	}
+}
+
+// CacheStats reports the exercise cache counters.
+func (s *FitnessService) CacheStats() cache.Stats {
+	return s.cache.Stats()
+}
+
+// PurgeCache drops cached exercises. With an empty muscle everything is dropped; otherwise every
+// entry (including filtered variants) for the muscle and its aliases. Returns the number removed.
+func (s *FitnessService) PurgeCache(ctx context.Context, muscle string) (int, error) {
+	muscle = normalizeMuscle(muscle)
+	if muscle == "" {
+		return s.cache.Purge(ctx)
+	}
+	names := []string{muscle}
+	if g, ok := s.muscles.Group(muscle); ok {
+		names = append([]string{g.Name}, g.Aliases...)
+	}
+	n := 0
+	for _, name := range names {
+		ok, err := s.cache.Delete(ctx, name)
+		if err != nil {
+			return n, err
+		}
+		if ok {
+			n++
+		}
+		removed, err := s.cache.DeletePrefix(ctx, name+"|")
+		n += removed
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
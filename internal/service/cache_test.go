@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, h http.Handler) *FitnessService {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	client := repository.NewWgerClient(srv.Client(), srv.URL, 2, "test")
+	return NewFitnessService(client, nil, jsonlog.New(io.Discard, jsonlog.LevelOff), "")
+}
+
+func TestExercisesFor_CoalescesAndServesStaleOnError(t *testing.T) {
+	var hits atomic.Int32
+	var failing atomic.Bool
+	release := make(chan struct{})
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, `{"count":1,"results":[{"id":7,"name":"Push-up","muscles":[4]}]}`)
+	}))
+
+	joined := make(chan struct{}, 10)
+	svc.flight.joined = func(string) { joined <- struct{}{} }
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{}); err != nil {
+				t.Errorf("GetExercisesByMuscle: %v", err)
+			}
+		}()
+	}
+	// all ten requests wait on the one fetch before wger answers
+	for i := 0; i < 10; i++ {
+		<-joined
+	}
+	svc.flight.joined = nil
+	close(release)
+	wg.Wait()
+	// one primary and one secondary call for all ten requests
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("expected 2 upstream calls, got %d", n)
+	}
+
+	// push the entry past the revalidation window and break wger
+	key := cacheKeyFor("chest", repository.ExerciseFilter{})
+	if _, ok := svc.getCache(ctx, key); !ok {
+		t.Fatalf("expected %q to be cached", key)
+	}
+	svc.ttl, svc.staleWhileRevalidate = 0, 0
+	failing.Store(true)
+
+	resp, err := svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{})
+	if err != nil {
+		t.Fatalf("expected stale data, got error %v", err)
+	}
+	if !resp.Stale || resp.Age <= 0 || len(resp.Exercises) != 1 {
+		t.Fatalf("expected one stale exercise with its age, got stale=%v age=%v n=%d", resp.Stale, resp.Age, len(resp.Exercises))
+	}
+}
+
+func TestGetExercisesByMuscle_PartialWhenSecondaryFails(t *testing.T) {
+	var secondaryDown atomic.Bool
+	secondaryDown.Store(true)
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("muscles_secondary") != "" {
+			if secondaryDown.Load() {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = io.WriteString(w, `{"count":1,"results":[{"id":9,"name":"Dip","muscles":[5],"muscles_secondary":[4]}]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"count":1,"results":[{"id":7,"name":"Push-up","muscles":[4]}]}`)
+	}))
+
+	ctx := context.Background()
+	resp, err := svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{})
+	if err != nil {
+		t.Fatalf("expected primary results, got error %v", err)
+	}
+	if !resp.Partial || len(resp.Warnings) != 1 || len(resp.Exercises) != 1 {
+		t.Fatalf("expected one partial result with a warning, got partial=%v warnings=%v n=%d", resp.Partial, resp.Warnings, len(resp.Exercises))
+	}
+	if _, ok := svc.getCache(ctx, cacheKeyFor("chest", repository.ExerciseFilter{})); ok {
+		t.Fatal("partial results must not be cached")
+	}
+
+	secondaryDown.Store(false)
+	resp, err = svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{})
+	if err != nil || resp.Partial || len(resp.Exercises) != 2 {
+		t.Fatalf("expected the full set once wger recovers, got partial=%v n=%d err=%v", resp.Partial, len(resp.Exercises), err)
+	}
+
+	// with the complete set due for revalidation, a partial answer doesn't replace it
+	svc.ttl, svc.staleWhileRevalidate = 0, 0
+	secondaryDown.Store(true)
+	resp, err = svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{})
+	if err != nil || resp.Partial || !resp.Stale || len(resp.Exercises) != 2 {
+		t.Fatalf("expected the complete stale set, got partial=%v stale=%v n=%d err=%v", resp.Partial, resp.Stale, len(resp.Exercises), err)
+	}
+}
+
+func TestGetExercisesByMuscle_StoreFallbackReportsAge(t *testing.T) {
+	ctx := context.Background()
+	db, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "fallback.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store, err := repository.NewExerciseStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertExercises(ctx, []models.Exercise{{ID: 7, Name: "Push-up", Muscles: []int{4}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE exercises SET updated_at = ?`, time.Now().Add(-time.Hour).Unix()); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	client := repository.NewWgerClient(srv.Client(), srv.URL, 2, "test")
+	svc := NewFitnessService(client, store, jsonlog.New(io.Discard, jsonlog.LevelOff), "")
+
+	resp, err := svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{})
+	if err != nil {
+		t.Fatalf("expected stored exercises, got %v", err)
+	}
+	if !resp.Stale || resp.Age < time.Hour || len(resp.Exercises) != 1 {
+		t.Fatalf("expected the stored exercise an hour old, got stale=%v age=%v n=%d", resp.Stale, resp.Age, len(resp.Exercises))
+	}
+}
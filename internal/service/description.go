@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/pkg/util"
+)
+
+// DescriptionFormat selects how wger's HTML exercise descriptions are rendered.
+type DescriptionFormat string
+
+const (
+	// FormatHTML keeps the markup, reduced to a whitelist of tags.
+	FormatHTML     DescriptionFormat = "html"
+	FormatText     DescriptionFormat = "text"
+	FormatMarkdown DescriptionFormat = "markdown"
+)
+
+// ParseDescriptionFormat validates a format query value; an empty value means FormatHTML.
+func ParseDescriptionFormat(s string) (DescriptionFormat, bool) {
+	switch f := DescriptionFormat(s); f {
+	case "":
+		return FormatHTML, true
+	case FormatHTML, FormatText, FormatMarkdown:
+		return f, true
+	}
+	return "", false
+}
+
+// renderDescriptions rewrites the descriptions of exs in place; callers pass a copy of cached data.
+func renderDescriptions(exs []models.Exercise, format DescriptionFormat) []models.Exercise {
+	render := util.SanitizeHTML
+	switch format {
+	case FormatText:
+		render = util.HTMLToText
+	case FormatMarkdown:
+		render = util.HTMLToMarkdown
+	}
+	for i := range exs {
+		exs[i].Description = render(exs[i].Description)
+	}
+	return exs
+}
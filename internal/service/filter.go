@@ -0,0 +1,190 @@
+package service
+
+import (
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Role selects whether the requested muscle must be worked as a primary or a secondary muscle.
+type Role string
+
+const (
+	RoleAny       Role = "any"
+	RolePrimary   Role = "primary"
+	RoleSecondary Role = "secondary"
+)
+
+// ParseRole validates a role query value; an empty value means RoleAny.
+func ParseRole(s string) (Role, bool) {
+	switch r := Role(s); r {
+	case "":
+		return RoleAny, true
+	case RoleAny, RolePrimary, RoleSecondary:
+		return r, true
+	}
+	return "", false
+}
+
+// SortOrder orders the exercises returned for a muscle. Every order breaks ties by ID, so identical
+// requests always page the same way.
+type SortOrder string
+
+const (
+	SortID   SortOrder = "id"
+	SortName SortOrder = "name"
+	// SortRelevance puts exercises working the muscle as a primary muscle first, then ranks by
+	// how many of the requested muscle IDs they hit.
+	SortRelevance SortOrder = "relevance"
+)
+
+// ParseSort validates a sort query value; an empty value means SortID.
+func ParseSort(s string) (SortOrder, bool) {
+	switch o := SortOrder(s); o {
+	case "":
+		return SortID, true
+	case SortID, SortName, SortRelevance:
+		return o, true
+	}
+	return "", false
+}
+
+// pushdown returns the part of q that wger can filter on. wger only accepts a single
+// category/equipment value, so lists are filtered locally.
+func (q ExerciseQuery) pushdown() repository.ExerciseFilter {
+	var f repository.ExerciseFilter
+	if len(q.Category) == 1 {
+		f.Category = q.Category[0]
+	}
+	if len(q.Equipment) == 1 {
+		f.Equipment = q.Equipment[0]
+	}
+	switch q.Role {
+	case RolePrimary:
+		f.SkipSecondary = true
+	case RoleSecondary:
+		f.SkipPrimary = true
+	}
+	return f
+}
+
+func (q ExerciseQuery) filtered() bool {
+	return len(q.Category) > 0 || len(q.Equipment) > 0 || len(q.ExcludeEquipment) > 0 ||
+		(q.Role != "" && q.Role != RoleAny)
+}
+
+// applyFilters returns the exercises matching q; ids are the requested muscle IDs used for the role check.
+// Equipment matches when the exercise uses any of the listed items.
+func applyFilters(exs []models.Exercise, ids []int, q ExerciseQuery) []models.Exercise {
+	if !q.filtered() {
+		return exs
+	}
+	out := make([]models.Exercise, 0, len(exs))
+	for _, e := range exs {
+		if len(q.Category) > 0 && !slices.Contains(q.Category, e.Category) {
+			continue
+		}
+		if len(q.Equipment) > 0 && !containsAny(e.Equipment, q.Equipment) {
+			continue
+		}
+		if containsAny(e.Equipment, q.ExcludeEquipment) {
+			continue
+		}
+		primary := containsAny(e.Muscles, ids)
+		switch q.Role {
+		case RolePrimary:
+			if !primary {
+				continue
+			}
+		case RoleSecondary:
+			if primary || !containsAny(e.MusclesSecondary, ids) {
+				continue
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// sortExercises returns a sorted copy of exs; ids are the requested muscle IDs used for relevance.
+func sortExercises(exs []models.Exercise, ids []int, order SortOrder) []models.Exercise {
+	out := slices.Clone(exs)
+	switch order {
+	case SortName:
+		slices.SortStableFunc(out, func(a, b models.Exercise) int {
+			if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+				return c
+			}
+			return a.ID - b.ID
+		})
+	case SortRelevance:
+		slices.SortStableFunc(out, func(a, b models.Exercise) int {
+			ra, rb := relevance(a, ids), relevance(b, ids)
+			switch {
+			case ra > rb:
+				return -1
+			case ra < rb:
+				return 1
+			}
+			return a.ID - b.ID
+		})
+	default:
+		slices.SortStableFunc(out, func(a, b models.Exercise) int { return a.ID - b.ID })
+	}
+	return out
+}
+
+// relevance scores how directly e trains the requested muscles. Primary hits count double, and any
+// primary hit outranks every secondary-only exercise; exercises spreading over many muscles rank
+// below focused ones with the same hits.
+func relevance(e models.Exercise, ids []int) float64 {
+	primary, secondary := overlap(e.Muscles, ids), overlap(e.MusclesSecondary, ids)
+	if primary+secondary == 0 {
+		return 0
+	}
+	score := float64(2*primary+secondary) / float64(2*len(ids)) // 0..1
+	score -= 0.01 * float64(len(e.Muscles)+len(e.MusclesSecondary)-primary-secondary)
+	score = max(score, 0.001)
+	if primary > 0 {
+		score++
+	}
+	return score
+}
+
+func overlap(have, want []int) int {
+	n := 0
+	for _, w := range want {
+		if slices.Contains(have, w) {
+			n++
+		}
+	}
+	return n
+}
+
+func containsAny(have, want []int) bool {
+	for _, w := range want {
+		if slices.Contains(have, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterKey encodes the pushed-down filter so differently filtered wger results are cached apart.
+// The language is not part of it; see cacheKeyFor.
+func filterKey(f repository.ExerciseFilter) string {
+	f.Language = 0
+	if f == (repository.ExerciseFilter{}) {
+		return ""
+	}
+	key := "c=" + strconv.Itoa(f.Category) + ",e=" + strconv.Itoa(f.Equipment)
+	switch {
+	case f.SkipPrimary:
+		key += ",r=secondary"
+	case f.SkipSecondary:
+		key += ",r=primary"
+	}
+	return key
+}
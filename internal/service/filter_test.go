@@ -0,0 +1,76 @@
+package service
+
+import (
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"testing"
+)
+
+func TestApplyFilters(t *testing.T) {
+	exs := []models.Exercise{
+		{ID: 1, Category: 11, Muscles: []int{4}, Equipment: []int{1}},                                // barbell bench
+		{ID: 2, Category: 11, Muscles: []int{4}, Equipment: []int{3}},                                // dumbbell press
+		{ID: 3, Category: 11, Muscles: []int{5}, MusclesSecondary: []int{4}, Equipment: []int{3, 8}}, // dumbbell skull crusher
+		{ID: 4, Category: 13, Muscles: []int{2}, MusclesSecondary: []int{4}},                         // bodyweight
+	}
+
+	tests := []struct {
+		name string
+		q    ExerciseQuery
+		want []int
+	}{
+		{"no filters", ExerciseQuery{}, []int{1, 2, 3, 4}},
+		{"equipment any-of", ExerciseQuery{Equipment: []int{3}}, []int{2, 3}},
+		{"dumbbells only", ExerciseQuery{Equipment: []int{3}, ExcludeEquipment: []int{8}}, []int{2}},
+		{"category", ExerciseQuery{Category: []int{13}}, []int{4}},
+		{"primary role", ExerciseQuery{Role: RolePrimary}, []int{1, 2}},
+		{"secondary role", ExerciseQuery{Role: RoleSecondary}, []int{3, 4}},
+		{"combined", ExerciseQuery{Role: RoleSecondary, Equipment: []int{3}}, []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyFilters(exs, []int{4}, tt.q)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d exercises, want %v", len(got), tt.want)
+			}
+			for i, e := range got {
+				if e.ID != tt.want[i] {
+					t.Fatalf("position %d: got id %d, want %v", i, e.ID, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSortExercises(t *testing.T) {
+	exs := []models.Exercise{
+		{ID: 5, Name: "skull crusher", Muscles: []int{5}, MusclesSecondary: []int{4}},
+		{ID: 3, Name: "Bench press", Muscles: []int{4}, MusclesSecondary: []int{5, 2}},
+		{ID: 9, Name: "cable fly", Muscles: []int{4}},
+		{ID: 1, Name: "Arnold press", Muscles: []int{2}, MusclesSecondary: []int{4, 5}},
+		{ID: 7, Name: "Pec deck", Muscles: []int{4, 10}},
+	}
+	ids := []int{4, 10}
+
+	tests := []struct {
+		order SortOrder
+		want  []int
+	}{
+		{SortID, []int{1, 3, 5, 7, 9}},
+		{SortName, []int{1, 3, 9, 7, 5}},
+		// both IDs as primary, then one primary hit (focused first), then secondary-only hits
+		{SortRelevance, []int{7, 9, 3, 5, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			got := sortExercises(exs, ids, tt.order)
+			for i, e := range got {
+				if e.ID != tt.want[i] {
+					t.Fatalf("position %d: got id %d, want %v", i, e.ID, tt.want)
+				}
+			}
+		})
+	}
+	if exs[0].ID != 5 {
+		t.Fatal("sortExercises must not reorder its input")
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flightGroup coalesces concurrent calls for the same key into a single execution.
+type flightGroup[T any] struct {
+	mu      sync.Mutex
+	calls   map[string]*flightCall[T]
+	timeout time.Duration
+	// joined, when set, is told about every caller once it waits on key; tests use it to sync.
+	joined func(key string)
+}
+
+type flightCall[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+func newFlightGroup[T any](timeout time.Duration) *flightGroup[T] {
+	return &flightGroup[T]{calls: make(map[string]*flightCall[T]), timeout: timeout}
+}
+
+// Do runs fn once per key at a time; concurrent callers wait for the same result.
+// fn runs detached from ctx (bounded by the group timeout), so one caller giving up doesn't fail
+// the others; each caller still stops waiting when its own ctx is done.
+func (g *flightGroup[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		c = &flightCall[T]{done: make(chan struct{})}
+		g.calls[key] = c
+		go g.run(context.WithoutCancel(ctx), key, c, fn)
+	}
+	g.mu.Unlock()
+	if g.joined != nil {
+		g.joined(key)
+	}
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func (g *flightGroup[T]) run(ctx context.Context, key string, c *flightCall[T], fn func(ctx context.Context) (T, error)) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.val, c.err = fn(ctx)
+}
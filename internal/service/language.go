@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NegotiateLanguage picks the supported language the Accept-Language header prefers most, by
+// q-value and then by order. It returns "" when none is supported.
+func NegotiateLanguage(header string) string {
+	type candidate struct {
+		code string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		if _, ok := repository.LanguageID(tag); ok && q > 0 {
+			candidates = append(candidates, candidate{tag, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+	return candidates[0].code
+}
+
+// languageFor maps a requested language code to the wger ID to query, 0 meaning the client default.
+func (s *FitnessService) languageFor(code string) int {
+	id, ok := repository.LanguageID(code)
+	if !ok || id == s.client.Language() {
+		return 0
+	}
+	return id
+}
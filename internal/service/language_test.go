@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                            "",
+		"de":                          "de",
+		"fr-CH, fr;q=0.9, en;q=0.8":   "fr-CH",
+		"xx, en;q=0.5, de;q=0.7":      "de",
+		"ja, zh;q=0.9":                "",
+		"de;q=0, en;q=0.1":            "en",
+		"nb-NO;q=0.8, pt-BR;q=bad, *": "nb-NO",
+	}
+	for header, want := range tests {
+		if got := NegotiateLanguage(header); got != want {
+			t.Errorf("NegotiateLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestGetExercisesByMuscle_LanguageFallsBackToEnglish(t *testing.T) {
+	var mu sync.Mutex
+	var asked []string
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := r.URL.Query().Get("language")
+		mu.Lock()
+		asked = append(asked, lang)
+		mu.Unlock()
+		switch {
+		case lang == "12":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case lang == "1" && r.URL.Query().Get("muscles") == "4":
+			_, _ = io.WriteString(w, `{"count":1,"results":[{"id":70,"name":"Liegestütz","muscles":[4],"exercise_base":7}]}`)
+		case lang == "2" && r.URL.Query().Get("muscles") == "4":
+			_, _ = io.WriteString(w, `{"count":2,"results":[{"id":7,"name":"Push-up","muscles":[4],"exercise_base":7},`+
+				`{"id":9,"name":"Bench Press","muscles":[4],"exercise_base":9}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"count":0,"results":[]}`)
+		}
+	}))
+	ctx := context.Background()
+	calls := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(asked)
+	}
+
+	// wger is down for French and nothing English is cached yet: no live English retry
+	if _, err := svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{Language: "fr"}); err == nil {
+		t.Fatal("expected the upstream error")
+	}
+	if got := calls(); slices.Contains(got, "2") {
+		t.Fatalf("English must not be fetched during an outage, got %v", got)
+	}
+	n := len(calls())
+
+	// the untranslated bench press is filled in from English
+	resp, err := svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{Language: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Language != "de" || len(resp.Exercises) != 2 || resp.Exercises[0].Name != "Bench Press" || resp.Exercises[1].Name != "Liegestütz" {
+		t.Fatalf("expected Liegestütz plus the English bench press, got %q %+v", resp.Language, resp.Exercises)
+	}
+	if item, ok := svc.getCache(ctx, cacheKeyFor("chest", repository.ExerciseFilter{})); !ok || len(item.data) != 2 || item.data[0].Name != "Push-up" {
+		t.Fatalf("expected only English under the default key, got %+v", item.data)
+	}
+
+	// no Spanish translations: English is served
+	resp, err = svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{Language: "es"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Language != "en" || len(resp.Exercises) != 2 || resp.Exercises[0].Name != "Push-up" {
+		t.Fatalf("expected the English fallback, got %q %+v", resp.Language, resp.Exercises)
+	}
+	if got := calls()[n:]; len(got) != 6 {
+		t.Fatalf("expected 2 calls per language, got %v", got)
+	}
+
+	// French fails again, now English comes from the cache without another call
+	resp, err = svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{Language: "fr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Language != "en" || len(resp.Exercises) != 2 {
+		t.Fatalf("expected cached English, got %q %+v", resp.Language, resp.Exercises)
+	}
+	if got := calls()[n+6:]; slices.Contains(got, "2") {
+		t.Fatalf("English must come from the cache, got %v", got)
+	}
+}
+
+func TestGetExercisesByMuscle_ExerciseInfoLocalizesCachedSet(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Query().Get("muscles") != "4" {
+			_, _ = io.WriteString(w, `{"count":0,"results":[]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"count":1,"results":[{"id":73,"category":{"id":11},"muscles":[{"id":4}],"translations":[`+
+			`{"name":"Bankdrücken","description":"<p>Auf eine Bank legen</p>","language":1},`+
+			`{"name":"Bench Press","description":"<p>Lay down</p>","language":2,"aliases":[{"alias":"Barbell bench press"}]}]}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	client := repository.NewWgerClient(srv.Client(), srv.URL, 2, "test").WithSchema(repository.SchemaExerciseInfo)
+	svc := NewFitnessService(client, nil, jsonlog.New(io.Discard, jsonlog.LevelOff), "")
+	ctx := context.Background()
+
+	for _, tt := range []struct{ lang, name, language string }{
+		{"de", "Bankdrücken", "de"},
+		{"fr", "Bench Press", "en"},
+		{"", "Bench Press", "en"},
+	} {
+		resp, err := svc.GetExercisesByMuscle(ctx, "chest", ExerciseQuery{Language: tt.lang})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := resp.Exercises[0]
+		if e.Name != tt.name || e.Language != tt.language {
+			t.Fatalf("lang %q: got %q in %q", tt.lang, e.Name, e.Language)
+		}
+		if len(e.Translations) != 2 || e.Translations[0].Description != "" || e.Translations[1].Aliases != nil {
+			t.Fatalf("lang %q: translations should only carry names, got %+v", tt.lang, e.Translations)
+		}
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("expected one fetch (2 legs) shared by every language, got %d calls", n)
+	}
+	item, _ := svc.getCache(ctx, cacheKeyFor("chest", repository.ExerciseFilter{}))
+	if item.data[0].Translations[0].Description == "" {
+		t.Fatal("the cached set must keep the translation bodies")
+	}
+}
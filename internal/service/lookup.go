@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"sync"
+	"time"
+)
+
+// Lookups resolves wger category and equipment IDs into names.
+// Tables are fetched through the wger client, cached for ttl and mirrored into the store
+// (when one is configured) so names are still available while wger is down.
+type Lookups struct {
+	client *repository.WgerClient
+	store  *repository.ExerciseStore
+	logger *jsonlog.Logger
+	ttl    time.Duration
+
+	refreshMu  sync.Mutex
+	mu         sync.RWMutex
+	categories map[int]string
+	equipment  map[int]string
+	loadedAt   time.Time
+}
+
+func NewLookups(client *repository.WgerClient, store *repository.ExerciseStore, logger *jsonlog.Logger) *Lookups {
+	return &Lookups{
+		client:     client,
+		store:      store,
+		logger:     logger,
+		ttl:        24 * time.Hour,
+		categories: map[int]string{},
+		equipment:  map[int]string{},
+	}
+}
+
+// Expand returns a copy of exs with CategoryDetail and/or EquipmentDetail filled in.
+// IDs without a known name are still returned, with an empty name.
+func (l *Lookups) Expand(ctx context.Context, exs []models.Exercise, category, equipment bool) []models.Exercise {
+	out := make([]models.Exercise, len(exs))
+	copy(out, exs)
+	if !category && !equipment {
+		return out
+	}
+	l.ensure(ctx)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for i := range out {
+		e := &out[i]
+		if category && e.Category != 0 {
+			e.CategoryDetail = &models.Lookup{ID: e.Category, Name: l.categories[e.Category]}
+		}
+		if equipment && len(e.Equipment) > 0 {
+			e.EquipmentDetail = make([]models.Lookup, 0, len(e.Equipment))
+			for _, id := range e.Equipment {
+				e.EquipmentDetail = append(e.EquipmentDetail, models.Lookup{ID: id, Name: l.equipment[id]})
+			}
+		}
+	}
+	return out
+}
+
+// ensure refreshes the tables when they are older than ttl. Names loaded earlier keep being served
+// while a background refresh runs; only the very first load waits, bounded by lookupTimeout.
+// Only one caller refreshes at a time.
+func (l *Lookups) ensure(ctx context.Context) {
+	if l.fresh() {
+		return
+	}
+	if l.loaded() {
+		if l.refreshMu.TryLock() {
+			go func() {
+				defer l.refreshMu.Unlock()
+				ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+				defer cancel()
+				l.refresh(ctx)
+			}()
+		}
+		return
+	}
+	l.refreshMu.Lock()
+	defer l.refreshMu.Unlock()
+	if l.loaded() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	l.refresh(ctx)
+}
+
+// lookupTimeout bounds a refresh so a hanging wger can't hold up requests that expand names.
+const lookupTimeout = 5 * time.Second
+
+// refresh reloads the tables from wger, falling back to the store while nothing is loaded.
+func (l *Lookups) refresh(ctx context.Context) {
+	if l.fresh() {
+		return
+	}
+	categories, catErr := l.client.FetchCategories(ctx)
+	equipment, eqErr := l.client.FetchEquipment(ctx)
+	if catErr == nil && eqErr == nil {
+		l.set(categories, equipment)
+		l.persist(ctx, categories, equipment)
+		return
+	}
+	l.logger.PrintError("failed to refresh lookups from wger", map[string]string{
+		"error": errors.Join(catErr, eqErr).Error(),
+	})
+
+	// keep whatever we have; otherwise fall back to the mirrored names
+	defer l.retryIn(lookupRetry)
+	l.mu.RLock()
+	empty := len(l.categories) == 0 && len(l.equipment) == 0
+	l.mu.RUnlock()
+	if !empty || l.store == nil {
+		return
+	}
+	categories, catErr = l.store.Categories(ctx)
+	equipment, eqErr = l.store.Equipment(ctx)
+	if catErr != nil || eqErr != nil {
+		l.logger.PrintError("failed to read lookups from store", map[string]string{
+			"error": errors.Join(catErr, eqErr).Error(),
+		})
+		return
+	}
+	l.set(categories, equipment)
+}
+
+// lookupRetry is how long to wait before asking wger again after a failed refresh.
+const lookupRetry = time.Minute
+
+// retryIn marks the tables as expiring after d so a failing wger isn't hit on every request.
+func (l *Lookups) retryIn(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.loadedAt = time.Now().Add(d - l.ttl)
+}
+
+// loaded reports whether a refresh has run, successful or not.
+func (l *Lookups) loaded() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return !l.loadedAt.IsZero()
+}
+
+func (l *Lookups) fresh() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return !l.loadedAt.IsZero() && time.Since(l.loadedAt) < l.ttl
+}
+
+func (l *Lookups) set(categories, equipment []models.Lookup) {
+	c := make(map[int]string, len(categories))
+	for _, it := range categories {
+		c[it.ID] = it.Name
+	}
+	e := make(map[int]string, len(equipment))
+	for _, it := range equipment {
+		e[it.ID] = it.Name
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.categories = c
+	l.equipment = e
+	l.loadedAt = time.Now()
+}
+
+func (l *Lookups) persist(ctx context.Context, categories, equipment []models.Lookup) {
+	if l.store == nil {
+		return
+	}
+	if err := l.store.UpsertCategories(ctx, categories); err != nil {
+		l.logger.PrintError("failed to store categories", map[string]string{"error": err.Error()})
+	}
+	if err := l.store.UpsertEquipment(ctx, equipment); err != nil {
+		l.logger.PrintError("failed to store equipment", map[string]string{"error": err.Error()})
+	}
+}
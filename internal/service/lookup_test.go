@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookups_ExpandRefreshesAndBacksOff(t *testing.T) {
+	var hits atomic.Int32
+	var failing atomic.Bool
+	var barbell atomic.Value
+	barbell.Store("Barbell")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/exercisecategory/":
+			_, _ = io.WriteString(w, `{"count":1,"results":[{"id":11,"name":"Chest"}]}`)
+		case "/equipment/":
+			_, _ = io.WriteString(w, `{"count":1,"results":[{"id":1,"name":"`+barbell.Load().(string)+`"}]}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client := repository.NewWgerClient(srv.Client(), srv.URL, 2, "test")
+	l := NewLookups(client, nil, jsonlog.New(io.Discard, jsonlog.LevelOff))
+	ctx := context.Background()
+	exs := []models.Exercise{{ID: 192, Category: 11, Equipment: []int{1, 99}}}
+
+	if got := l.Expand(ctx, exs, false, false); got[0].CategoryDetail != nil || hits.Load() != 0 {
+		t.Fatal("nothing to expand must not call wger")
+	}
+	got := l.Expand(ctx, exs, true, true)
+	if got[0].CategoryDetail == nil || got[0].CategoryDetail.Name != "Chest" ||
+		len(got[0].EquipmentDetail) != 2 || got[0].EquipmentDetail[0].Name != "Barbell" || got[0].EquipmentDetail[1].Name != "" {
+		t.Fatalf("expanded: %+v", got[0])
+	}
+	if exs[0].CategoryDetail != nil {
+		t.Fatal("Expand must not modify its input")
+	}
+	l.Expand(ctx, exs, true, true)
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("fresh tables must not be refetched, got %d calls", n)
+	}
+
+	// stale names are served at once while the refresh runs in the background
+	barbell.Store("Olympic bar")
+	l.mu.Lock()
+	l.loadedAt = time.Now().Add(-2 * l.ttl)
+	l.mu.Unlock()
+	if got := l.Expand(ctx, exs, false, true); got[0].EquipmentDetail[0].Name != "Barbell" {
+		t.Fatalf("expected the stale name, got %+v", got[0].EquipmentDetail)
+	}
+	l.refreshMu.Lock() // held by the background refresh until it is done
+	l.refreshMu.Unlock()
+	if got := l.Expand(ctx, exs, false, true); got[0].EquipmentDetail[0].Name != "Olympic bar" {
+		t.Fatalf("expected the refreshed name, got %+v", got[0].EquipmentDetail)
+	}
+
+	// a failed refresh keeps the names and waits lookupRetry before asking again
+	failing.Store(true)
+	l.mu.Lock()
+	l.loadedAt = time.Now().Add(-2 * l.ttl)
+	l.mu.Unlock()
+	before := hits.Load()
+	l.Expand(ctx, exs, false, true)
+	l.refreshMu.Lock()
+	l.refreshMu.Unlock()
+	if got := l.Expand(ctx, exs, false, true); got[0].EquipmentDetail[0].Name != "Olympic bar" {
+		t.Fatalf("a failed refresh must keep the names, got %+v", got[0].EquipmentDetail)
+	}
+	if n := hits.Load() - before; n != 2 {
+		t.Fatalf("expected one failed refresh (2 calls) and then a back-off, got %d calls", n)
+	}
+}
+
+func TestLookups_FallBackToStore(t *testing.T) {
+	ctx := context.Background()
+	db, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "lookups.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store, err := repository.NewExerciseStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertEquipment(ctx, []models.Lookup{{ID: 1, Name: "Barbell"}}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+	l := NewLookups(repository.NewWgerClient(srv.Client(), srv.URL, 2, "test"), store, jsonlog.New(io.Discard, jsonlog.LevelOff))
+
+	got := l.Expand(ctx, []models.Exercise{{ID: 192, Equipment: []int{1}}}, false, true)
+	if got[0].EquipmentDetail[0].Name != "Barbell" {
+		t.Fatalf("expected the mirrored name, got %+v", got[0].EquipmentDetail)
+	}
+}
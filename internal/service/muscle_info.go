@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+)
+
+// MuscleCatalog describes every known muscle and aggregate group. Stored exercises are counted
+// for all groups in one query.
+func (s *FitnessService) MuscleCatalog(ctx context.Context) []models.MuscleInfo {
+	groups := s.muscles.Groups()
+	var ids []int
+	for _, g := range groups {
+		ids = append(ids, g.IDs...)
+	}
+	stored := s.storedByMuscle(ctx, ids)
+	out := make([]models.MuscleInfo, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, s.muscleInfo(ctx, g, stored))
+	}
+	return out
+}
+
+// MuscleInfo describes a single muscle looked up by name or alias.
+func (s *FitnessService) MuscleInfo(ctx context.Context, name string) (models.MuscleInfo, bool) {
+	g, ok := s.muscles.Group(name)
+	if !ok {
+		return models.MuscleInfo{}, false
+	}
+	return s.muscleInfo(ctx, g, s.storedByMuscle(ctx, g.IDs)), true
+}
+
+func (s *FitnessService) muscleInfo(ctx context.Context, g MuscleGroup, stored map[int][]int) models.MuscleInfo {
+	info := models.MuscleInfo{
+		Name:          g.Name,
+		IDs:           g.IDs,
+		Aliases:       g.Aliases,
+		Aggregate:     g.Aggregate(),
+		ExerciseCount: s.exerciseCount(ctx, g, stored),
+	}
+	if g.Muscle != nil {
+		front := g.Muscle.IsFront
+		info.LatinName = g.Muscle.Name
+		info.EnglishName = g.Muscle.NameEn
+		info.IsFront = &front
+	}
+	for _, n := range append([]string{g.Name}, g.Aliases...) {
+		if similar, ok := s.similarMuscles[n]; ok {
+			info.SimilarMuscles = similar
+			break
+		}
+	}
+	return info
+}
+
+// storedByMuscle reads the mirrored exercises per muscle; nil means there is no usable store.
+func (s *FitnessService) storedByMuscle(ctx context.Context, muscles []int) map[int][]int {
+	if s.store == nil {
+		return nil
+	}
+	byMuscle, err := s.store.ExerciseIDsByMuscle(ctx, muscles)
+	if err != nil {
+		s.logger.PrintError("failed to count exercises", map[string]string{"error": err.Error()})
+		return nil
+	}
+	return byMuscle
+}
+
+// exerciseCount prefers the SQLite mirror and falls back to whatever is cached in memory.
+func (s *FitnessService) exerciseCount(ctx context.Context, g MuscleGroup, stored map[int][]int) int {
+	if stored != nil {
+		seen := map[int]struct{}{}
+		for _, m := range g.IDs {
+			for _, id := range stored[m] {
+				seen[id] = struct{}{}
+			}
+		}
+		return len(seen)
+	}
+	for _, n := range append([]string{g.Name}, g.Aliases...) {
+		if item, ok := s.getCache(ctx, cacheKeyFor(n, repository.ExerciseFilter{})); ok {
+			return len(item.data)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// builtinMuscles is the offline table used until (or unless) a wger sync succeeds. After a sync,
+// names wger doesn't know (e.g. "forearms") keep resolving to these IDs.
+var builtinMuscles = map[string][]int{
+	"biceps":     {1},
+	"shoulders":  {2},
+	"chest":      {4},
+	"triceps":    {5},
+	"abs":        {6},
+	"calves":     {7},
+	"glutes":     {8},
+	"quadriceps": {10},
+	"quads":      {10},
+	"hamstrings": {11},
+	"lats":       {12},
+	"lower back": {13},
+	"trapezius":  {14},
+	"forearms":   {9},
+	"neck":       {3},
+	"back":       {12, 13, 14}, // aggregate
+}
+
+// muscleAliases is our naming layer on top of wger: each alias resolves to the union of the
+// IDs behind the listed (lower-case) wger names. Aliases naming several muscles are aggregate groups.
+var muscleAliases = map[string][]string{
+	"quads":      {"quads", "quadriceps femoris"},
+	"quadriceps": {"quads", "quadriceps femoris"},
+	"pecs":       {"chest", "pectoralis major"},
+	"delts":      {"shoulders", "anterior deltoid"},
+	"traps":      {"trapezius"},
+	"obliques":   {"obliquus externus abdominis"},
+	"back":       {"lats", "latissimus dorsi", "trapezius", "lower back"},
+	"legs":       {"quads", "hamstrings", "glutes", "calves", "soleus"},
+}
+
+// MuscleRegistry maps muscle names and aliases to wger muscle IDs.
+// It starts from builtinMuscles; every successful sync layers wger's /muscle/ data over it.
+type MuscleRegistry struct {
+	client *repository.WgerClient
+	logger *jsonlog.Logger
+
+	mu       sync.RWMutex
+	muscles  []models.Muscle
+	byName   map[string][]int
+	syncedAt time.Time
+}
+
+func NewMuscleRegistry(client *repository.WgerClient, logger *jsonlog.Logger) *MuscleRegistry {
+	r := &MuscleRegistry{client: client, logger: logger}
+	r.byName = withAliases(builtinMuscles)
+	return r
+}
+
+// Sync loads the muscle list from wger. On failure the current table is kept.
+func (r *MuscleRegistry) Sync(ctx context.Context) error {
+	muscles, err := r.client.FetchMuscles(ctx)
+	if err != nil {
+		return err
+	}
+	if len(muscles) == 0 {
+		return errors.New("wger returned no muscles")
+	}
+
+	names := make(map[string][]int, len(muscles)*2)
+	for _, m := range muscles {
+		for _, n := range []string{m.Name, m.NameEn} {
+			key := normalizeMuscle(n)
+			if key != "" && !slices.Contains(names[key], m.ID) {
+				names[key] = append(names[key], m.ID)
+			}
+		}
+	}
+	// built-in names wger lacks stay resolvable; aliases are rebuilt from the merged table below
+	for name, ids := range builtinMuscles {
+		if _, ok := names[name]; !ok && muscleAliases[name] == nil {
+			names[name] = ids
+		}
+	}
+	sort.Slice(muscles, func(i, j int) bool { return muscles[i].ID < muscles[j].ID })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.muscles = muscles
+	r.byName = withAliases(names)
+	r.syncedAt = time.Now()
+	return nil
+}
+
+// Run syncs immediately and then every interval until ctx is done. Failures are logged.
+func (r *MuscleRegistry) Run(ctx context.Context, interval time.Duration) {
+	syncOnce := func() {
+		sctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		if err := r.Sync(sctx); err != nil {
+			r.logger.PrintError("failed to sync muscles from wger, keeping current table", map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+		r.logger.PrintInfo("muscles synced from wger", nil)
+	}
+
+	syncOnce()
+	if interval <= 0 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			syncOnce()
+		}
+	}
+}
+
+// Resolve returns the wger IDs behind a muscle name or alias.
+func (r *MuscleRegistry) Resolve(name string) ([]int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids, ok := r.byName[normalizeMuscle(name)]
+	return ids, ok
+}
+
+// Names lists every resolvable muscle name and alias, sorted.
+func (r *MuscleRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Muscles returns the muscles last synced from wger (nil while on the built-in table).
+func (r *MuscleRegistry) Muscles() []models.Muscle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.muscles)
+}
+
+// SyncedAt reports when wger data was last loaded; zero means the built-in table is in use.
+func (r *MuscleRegistry) SyncedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.syncedAt
+}
+
+// MuscleGroup is one distinct set of wger IDs together with every name resolving to it.
+type MuscleGroup struct {
+	Name    string
+	Aliases []string
+	IDs     []int
+	// Muscle is set when the group is a single muscle synced from wger.
+	Muscle *models.Muscle
+}
+
+// Aggregate reports whether the group spans several wger muscles (e.g. "back").
+func (g MuscleGroup) Aggregate() bool {
+	return len(g.IDs) > 1
+}
+
+// Groups returns every muscle group, sorted by name.
+func (r *MuscleRegistry) Groups() []MuscleGroup {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make(map[string][]string)
+	ids := make(map[string][]int)
+	for name, set := range r.byName {
+		key := intsToKey(set)
+		names[key] = append(names[key], name)
+		ids[key] = set
+	}
+
+	out := make([]MuscleGroup, 0, len(names))
+	for key, group := range names {
+		out = append(out, r.newGroup(group, ids[key]))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Group returns the group a name or alias belongs to.
+func (r *MuscleRegistry) Group(name string) (MuscleGroup, bool) {
+	set, ok := r.Resolve(name)
+	if !ok {
+		return MuscleGroup{}, false
+	}
+	key := intsToKey(set)
+	for _, g := range r.Groups() {
+		if intsToKey(g.IDs) == key {
+			return g, true
+		}
+	}
+	return MuscleGroup{}, false
+}
+
+// newGroup picks the canonical name: wger's English name, then its Latin name, then the shortest alias.
+// Callers hold r.mu.
+func (r *MuscleRegistry) newGroup(names []string, ids []int) MuscleGroup {
+	g := MuscleGroup{IDs: ids}
+	if len(ids) == 1 {
+		for i := range r.muscles {
+			if r.muscles[i].ID == ids[0] {
+				m := r.muscles[i]
+				g.Muscle = &m
+				break
+			}
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	g.Name = names[0]
+	if g.Muscle != nil {
+		for _, preferred := range []string{normalizeMuscle(g.Muscle.NameEn), normalizeMuscle(g.Muscle.Name)} {
+			if preferred != "" && slices.Contains(names, preferred) {
+				g.Name = preferred
+				break
+			}
+		}
+	}
+	for _, n := range names {
+		if n != g.Name {
+			g.Aliases = append(g.Aliases, n)
+		}
+	}
+	sort.Strings(g.Aliases)
+	return g
+}
+
+func intsToKey(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+// withAliases returns names extended with every alias that resolves against it; an alias that
+// doesn't falls back to its built-in IDs. Existing names win over aliases of the same spelling.
+func withAliases(names map[string][]int) map[string][]int {
+	out := make(map[string][]int, len(names)+len(muscleAliases))
+	for name, ids := range names {
+		ids = slices.Clone(ids)
+		slices.Sort(ids)
+		out[name] = ids
+	}
+	for alias, targets := range muscleAliases {
+		if _, ok := out[alias]; ok {
+			continue
+		}
+		var ids []int
+		for _, t := range targets {
+			for _, id := range names[t] {
+				if !slices.Contains(ids, id) {
+					ids = append(ids, id)
+				}
+			}
+		}
+		if len(ids) == 0 {
+			ids = slices.Clone(builtinMuscles[alias])
+		}
+		if len(ids) > 0 {
+			slices.Sort(ids)
+			out[alias] = ids
+		}
+	}
+	return out
+}
+
+func normalizeMuscle(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMuscleRegistry_SyncAndFallback(t *testing.T) {
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, `{"count":4,"next":null,"results":[
+			{"id":12,"name":"Latissimus dorsi","name_en":"Lats","is_front":false},
+			{"id":9,"name":"Trapezius","name_en":"","is_front":false},
+			{"id":10,"name":"Quadriceps femoris","name_en":"Quads","is_front":true},
+			{"id":16,"name":"Rhomboids","name_en":"","is_front":false}]}`)
+	}))
+	defer srv.Close()
+
+	client := repository.NewWgerClient(srv.Client(), srv.URL, 2, "test")
+	reg := NewMuscleRegistry(client, jsonlog.New(io.Discard, jsonlog.LevelOff))
+
+	// before any sync the built-in table answers
+	if ids, ok := reg.Resolve("back"); !ok || !reflect.DeepEqual(ids, []int{12, 13, 14}) {
+		t.Fatalf("built-in back: got %v %v", ids, ok)
+	}
+
+	if err := reg.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	cases := map[string][]int{
+		"rhomboids":           {16},
+		"latissimus dorsi":    {12},
+		"Quadriceps  Femoris": {10},
+		"quadriceps":          {10},
+		"back":                {9, 12, 13},
+		"lower back":          {13},
+	}
+	for name, want := range cases {
+		if ids, ok := reg.Resolve(name); !ok || !reflect.DeepEqual(ids, want) {
+			t.Fatalf("Resolve(%q) = %v %v, want %v", name, ids, ok, want)
+		}
+	}
+	// every name that resolved on the built-in table still resolves after a sync
+	for _, name := range NewMuscleRegistry(nil, jsonlog.New(io.Discard, jsonlog.LevelOff)).Names() {
+		if _, ok := reg.Resolve(name); !ok {
+			t.Fatalf("%q no longer resolves after a sync", name)
+		}
+	}
+	if ids, _ := reg.Resolve("forearms"); !reflect.DeepEqual(ids, builtinMuscles["forearms"]) {
+		t.Fatalf("forearms should keep its built-in IDs, got %v", ids)
+	}
+	// an alias whose wger names are all missing keeps its built-in IDs too
+	if ids := withAliases(map[string][]int{"lats": {12}})["quads"]; !reflect.DeepEqual(ids, []int{10}) {
+		t.Fatalf("quads without wger data: got %v", ids)
+	}
+
+	// a failing sync keeps the last good table
+	down.Store(true)
+	if err := reg.Sync(context.Background()); err == nil {
+		t.Fatalf("expected sync error")
+	}
+	if _, ok := reg.Resolve("rhomboids"); !ok {
+		t.Fatalf("synced table lost after failed sync")
+	}
+}
+
+func TestMuscleRegistry_Groups(t *testing.T) {
+	reg := NewMuscleRegistry(nil, jsonlog.New(io.Discard, jsonlog.LevelOff))
+	groups := reg.Groups()
+	seen := map[string]bool{}
+	for i, g := range groups {
+		if i > 0 && groups[i-1].Name >= g.Name {
+			t.Fatalf("groups not sorted by name: %q before %q", groups[i-1].Name, g.Name)
+		}
+		if key := intsToKey(g.IDs); seen[key] {
+			t.Fatalf("IDs %v listed twice", g.IDs)
+		} else {
+			seen[key] = true
+		}
+	}
+
+	quads, ok := reg.Group("quadriceps")
+	if !ok || !reflect.DeepEqual(quads.IDs, []int{10}) || quads.Aggregate() {
+		t.Fatalf("quadriceps: %+v %v", quads, ok)
+	}
+	if other, _ := reg.Group("Quads"); other.Name != quads.Name {
+		t.Fatalf("aliases should share a group: %q vs %q", other.Name, quads.Name)
+	}
+	if back, ok := reg.Group("back"); !ok || !back.Aggregate() {
+		t.Fatalf("back should be an aggregate group: %+v", back)
+	}
+	if _, ok := reg.Group("wings"); ok {
+		t.Fatal("unknown muscle resolved")
+	}
+}
+
+func TestMuscleInfo_CountsStoredExercises(t *testing.T) {
+	ctx := context.Background()
+	db, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "muscles.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store, err := repository.NewExerciseStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// exercise 2 hits two back muscles and must count once for the group
+	if err := store.UpsertExercises(ctx, []models.Exercise{
+		{ID: 1, Name: "Pull-up", Muscles: []int{12}},
+		{ID: 2, Name: "Deadlift", Muscles: []int{13}, MusclesSecondary: []int{12}},
+		{ID: 3, Name: "Push-up", Muscles: []int{4}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewFitnessService(repository.NewWgerClient(nil, "", 2, "test"), store, jsonlog.New(io.Discard, jsonlog.LevelOff), "")
+
+	back, ok := svc.MuscleInfo(ctx, "back")
+	if !ok || back.ExerciseCount != 2 || !back.Aggregate {
+		t.Fatalf("back: %+v %v", back, ok)
+	}
+	if _, ok := svc.MuscleInfo(ctx, "wings"); ok {
+		t.Fatal("unknown muscle described")
+	}
+	counts := map[string]int{}
+	for _, m := range svc.MuscleCatalog(ctx) {
+		counts[m.Name] = m.ExerciseCount
+	}
+	chest, _ := svc.muscles.Group("chest")
+	if counts[back.Name] != 2 || counts[chest.Name] != 1 || counts["biceps"] != 0 {
+		t.Fatalf("catalog counts: %v", counts)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"slices"
+	"time"
+)
+
+// SplitType names a weekly training split.
+type SplitType string
+
+const (
+	SplitPPL        SplitType = "ppl"
+	SplitUpperLower SplitType = "upper_lower"
+	SplitFullBody   SplitType = "full_body"
+)
+
+type splitDay struct {
+	name    string
+	muscles []string
+}
+
+// splitTemplates lists each split's sessions in rotation order. Neighbouring sessions share no
+// muscle, so rotating them never trains a muscle on consecutive days.
+var splitTemplates = map[SplitType]struct {
+	days        []splitDay
+	defaultDays int
+	minDays     int
+	maxDays     int
+}{
+	SplitPPL: {
+		days: []splitDay{
+			{"push", []string{"chest", "shoulders", "triceps"}},
+			{"pull", []string{"lats", "trapezius", "biceps"}},
+			{"legs", []string{"quads", "hamstrings", "glutes", "calves"}},
+		},
+		defaultDays: 6, minDays: 3, maxDays: 6,
+	},
+	SplitUpperLower: {
+		days: []splitDay{
+			{"upper", []string{"chest", "lats", "shoulders", "biceps", "triceps"}},
+			{"lower", []string{"quads", "hamstrings", "glutes", "calves", "abs"}},
+		},
+		defaultDays: 4, minDays: 2, maxDays: 6,
+	},
+	SplitFullBody: {
+		days: []splitDay{
+			{"full body A", []string{"chest", "lats", "quads", "abs"}},
+			{"full body B", []string{"shoulders", "trapezius", "hamstrings", "glutes", "calves"}},
+		},
+		defaultDays: 3, minDays: 2, maxDays: 6,
+	},
+}
+
+// ParseSplitType validates a split type; an empty value means SplitPPL.
+func ParseSplitType(s string) (SplitType, bool) {
+	if s == "" {
+		return SplitPPL, true
+	}
+	_, ok := splitTemplates[SplitType(s)]
+	return SplitType(s), ok
+}
+
+// SplitDays returns the default and allowed number of training days per week for t.
+func SplitDays(t SplitType) (def, lo, hi int) {
+	tpl := splitTemplates[t]
+	return tpl.defaultDays, tpl.minDays, tpl.maxDays
+}
+
+// PlanRequest describes a weekly programme; Days is the number of training days (the rest are off).
+// Minutes, Equipment and Level apply to every session.
+type PlanRequest struct {
+	Type      SplitType
+	Days      int
+	Minutes   int
+	Equipment []int
+	Level     Level
+}
+
+// PlanSplit spreads the split's sessions over a Monday-to-Sunday week and fills each training day
+// with a generated workout. Training days are spaced evenly, with Sunday kept as rest so the week can
+// repeat, and each session avoids the muscles of the sessions before and after it.
+func (s *FitnessService) PlanSplit(ctx context.Context, req PlanRequest) (models.SplitPlan, error) {
+	tpl, ok := splitTemplates[req.Type]
+	if !ok {
+		return models.SplitPlan{}, fmt.Errorf("%w: unknown split type %q", models.ErrInvalidPlan, req.Type)
+	}
+	if req.Days < tpl.minDays || req.Days > tpl.maxDays {
+		return models.SplitPlan{}, fmt.Errorf("%w: %s needs %d to %d days", models.ErrInvalidPlan, req.Type, tpl.minDays, tpl.maxDays)
+	}
+
+	week := make([]*splitDay, 7)
+	for i := 0; i < req.Days; i++ {
+		week[i*7/req.Days] = &tpl.days[i%len(tpl.days)]
+	}
+	if err := checkRecovery(week); err != nil {
+		return models.SplitPlan{}, err
+	}
+
+	plan := models.SplitPlan{
+		Type:     string(req.Type),
+		Days:     req.Days,
+		Level:    string(req.Level),
+		Schedule: make([]models.PlanDay, 0, 7),
+	}
+	for i, d := range week {
+		day := models.PlanDay{Day: i + 1, Weekday: time.Weekday((i + 1) % 7).String()}
+		if d == nil {
+			day.Name = "rest"
+			day.Rest = true
+			plan.Schedule = append(plan.Schedule, day)
+			continue
+		}
+		var avoid []string
+		for _, n := range []*splitDay{week[(i+6)%7], week[(i+1)%7]} {
+			if n != nil {
+				avoid = append(avoid, n.muscles...)
+			}
+		}
+		w, err := s.GenerateWorkout(ctx, WorkoutRequest{
+			Muscles:   d.muscles,
+			Minutes:   req.Minutes,
+			Equipment: req.Equipment,
+			Level:     req.Level,
+			Avoid:     avoid,
+		})
+		if err != nil {
+			return models.SplitPlan{}, err
+		}
+		day.Name = d.name
+		day.Muscles = d.muscles
+		day.Workout = &w
+		plan.Stale = plan.Stale || w.Stale
+		plan.Schedule = append(plan.Schedule, day)
+	}
+	return plan, nil
+}
+
+// checkRecovery makes sure no muscle is trained on two consecutive days, counting Sunday to Monday.
+func checkRecovery(week []*splitDay) error {
+	for i, d := range week {
+		next := week[(i+1)%len(week)]
+		if d == nil || next == nil {
+			continue
+		}
+		for _, m := range d.muscles {
+			if slices.Contains(next.muscles, m) {
+				return fmt.Errorf("%w: %s would be trained on consecutive days", models.ErrInvalidPlan, m)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestPlanSplit_NoMuscleOnConsecutiveDays(t *testing.T) {
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// muscle m gets isolation exercise m*10+1 and press m*10+2, which also works the triceps (5)
+		m, err := strconv.Atoi(r.URL.Query().Get("muscles"))
+		if err != nil {
+			_, _ = io.WriteString(w, `{"count":0,"results":[]}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"count":2,"results":[
+			{"id":%d,"name":"Isolation %d","muscles":[%d]},
+			{"id":%d,"name":"Press %d","muscles":[%d,5]}]}`, m*10+1, m, m, m*10+2, m, m)
+	}))
+
+	for _, tc := range []struct {
+		typ  SplitType
+		days int
+	}{{SplitPPL, 3}, {SplitPPL, 6}, {SplitUpperLower, 4}, {SplitUpperLower, 6}, {SplitFullBody, 3}} {
+		plan, err := svc.PlanSplit(context.Background(), PlanRequest{Type: tc.typ, Days: tc.days, Minutes: 60, Level: LevelBeginner})
+		if err != nil {
+			t.Fatalf("%s/%d: %v", tc.typ, tc.days, err)
+		}
+		if len(plan.Schedule) != 7 {
+			t.Fatalf("%s/%d: %d days in schedule", tc.typ, tc.days, len(plan.Schedule))
+		}
+		training := 0
+		for i, d := range plan.Schedule {
+			if d.Rest {
+				continue
+			}
+			training++
+			if len(d.Workout.Compounds)+len(d.Workout.Accessories) == 0 {
+				t.Fatalf("%s/%d: %s has no exercises", tc.typ, tc.days, d.Weekday)
+			}
+			next := plan.Schedule[(i+1)%7]
+			if next.Rest {
+				continue
+			}
+			// primary muscles of one day's exercises must not show up as a target the next day
+			nextIDs := map[int]bool{}
+			for _, m := range next.Muscles {
+				ids, _ := svc.muscles.Resolve(m)
+				for _, id := range ids {
+					nextIDs[id] = true
+				}
+			}
+			for _, it := range append(d.Workout.Compounds, d.Workout.Accessories...) {
+				if id := it.ExerciseID; nextIDs[id/10] || id%10 == 2 && nextIDs[5] {
+					t.Fatalf("%s/%d: %s includes %q which works a muscle trained on %s", tc.typ, tc.days, d.Weekday, it.Name, next.Weekday)
+				}
+			}
+		}
+		if training != tc.days {
+			t.Fatalf("%s/%d: %d training days", tc.typ, tc.days, training)
+		}
+	}
+
+	if _, err := svc.PlanSplit(context.Background(), PlanRequest{Type: SplitPPL, Days: 7}); !errors.Is(err, models.ErrInvalidPlan) {
+		t.Fatalf("expected ErrInvalidPlan, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Record kinds tracked per exercise.
+const (
+	RecordE1RM   = "e1rm"
+	RecordWeight = "weight"
+	RecordVolume = "volume"
+)
+
+// SetQuery filters set history; Muscle is a muscle name or alias.
+type SetQuery struct {
+	ExerciseID int
+	Muscle     string
+	From, To   time.Time
+	Limit      int
+}
+
+// WithMuscles lets per-muscle history and volume use the muscle registry.
+func (s *UserService) WithMuscles(r *MuscleRegistry) *UserService {
+	s.muscles = r
+	return s
+}
+
+// estimate1RM uses the Epley formula. With an RPE the reps left in reserve (10 - RPE) are added,
+// so an RPE 8 triple counts like a five-rep max.
+func estimate1RM(weight float64, reps int, rpe *float64) float64 {
+	if weight <= 0 || reps <= 0 {
+		return 0
+	}
+	effective := float64(reps)
+	if rpe != nil {
+		effective += 10 - *rpe
+	}
+	if effective <= 1 {
+		return weight
+	}
+	return math.Round(weight*(1+effective/30)*10) / 10
+}
+
+// LogSet stores a set and reports which of the exercise's personal records it broke.
+// The first set of an exercise breaks nothing.
+func (s *UserService) LogSet(ctx context.Context, userID int64, set models.SetLog) (models.SetLog, error) {
+	set, prev, err := s.store.LogSet(ctx, userID, set)
+	if err != nil {
+		return models.SetLog{}, err
+	}
+	set.E1RM = estimate1RM(set.Weight, set.Reps, set.RPE)
+	if len(prev) > 0 {
+		old := recordsFor(prev)
+		for _, kind := range []string{RecordE1RM, RecordWeight, RecordVolume} {
+			if v := recordValue(kind, set); v > 0 && v > old[kind].Value {
+				set.Records = append(set.Records, kind)
+			}
+		}
+	}
+	return set, nil
+}
+
+func (s *UserService) DeleteSet(ctx context.Context, userID, id int64) error {
+	return s.store.DeleteSet(ctx, userID, id)
+}
+
+// Sets returns the user's set history, newest first.
+func (s *UserService) Sets(ctx context.Context, userID int64, q SetQuery) ([]models.SetLog, error) {
+	f := repository.SetFilter{ExerciseID: q.ExerciseID, From: q.From, To: q.To, Limit: q.Limit}
+	if q.Muscle != "" {
+		ids, err := s.resolveMuscle(q.Muscle)
+		if err != nil {
+			return nil, err
+		}
+		f.MuscleIDs = ids
+	}
+	sets, err := s.store.Sets(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sets {
+		sets[i].E1RM = estimate1RM(sets[i].Weight, sets[i].Reps, sets[i].RPE)
+	}
+	return sets, nil
+}
+
+// ExerciseProgress summarises an exercise per training day (UTC) with its personal records.
+func (s *UserService) ExerciseProgress(ctx context.Context, userID int64, exerciseID int) (models.ExerciseProgress, error) {
+	sets, err := s.Sets(ctx, userID, SetQuery{ExerciseID: exerciseID})
+	if err != nil {
+		return models.ExerciseProgress{}, err
+	}
+	if len(sets) == 0 {
+		return models.ExerciseProgress{}, fmt.Errorf("%w: no sets logged for exercise %d", models.ErrNotFound, exerciseID)
+	}
+	p := models.ExerciseProgress{
+		ExerciseID:   exerciseID,
+		ExerciseName: sets[0].ExerciseName,
+		Sets:         len(sets),
+		Records:      recordList(recordsFor(sets)),
+		History:      []models.ProgressPoint{},
+	}
+	// sets are newest first; walk them oldest first so history is chronological
+	for _, set := range slices.Backward(sets) {
+		day := set.PerformedAt.UTC().Format(time.DateOnly)
+		if n := len(p.History); n == 0 || p.History[n-1].Date != day {
+			p.History = append(p.History, models.ProgressPoint{Date: day})
+		}
+		pt := &p.History[len(p.History)-1]
+		pt.Sets++
+		pt.Volume += set.Weight * float64(set.Reps)
+		pt.BestE1RM = max(pt.BestE1RM, set.E1RM)
+	}
+	return p, nil
+}
+
+// Records lists the personal records of every exercise the user logged.
+func (s *UserService) Records(ctx context.Context, userID int64) ([]models.PersonalRecord, error) {
+	sets, err := s.Sets(ctx, userID, SetQuery{})
+	if err != nil {
+		return nil, err
+	}
+	byExercise := map[int][]models.SetLog{}
+	for _, set := range sets {
+		byExercise[set.ExerciseID] = append(byExercise[set.ExerciseID], set)
+	}
+	out := []models.PersonalRecord{}
+	for _, id := range slices.Sorted(maps.Keys(byExercise)) {
+		out = append(out, recordList(recordsFor(byExercise[id]))...)
+	}
+	return out, nil
+}
+
+// WeeklyVolume totals sets, reps and volume (weight x reps) per muscle group and week for the last
+// `weeks` weeks, oldest first. A set counts for every primary muscle of its exercise; with muscle
+// set only that group is reported. Sets of exercises missing from the local mirror have no known
+// muscles and are skipped.
+func (s *UserService) WeeklyVolume(ctx context.Context, userID int64, weeks int, muscle string) ([]models.WeeklyVolume, error) {
+	var only []int
+	if muscle != "" {
+		ids, err := s.resolveMuscle(muscle)
+		if err != nil {
+			return nil, err
+		}
+		only = ids
+		muscle = normalizeMuscle(muscle)
+	}
+	from := weekStart(time.Now()).AddDate(0, 0, -7*(weeks-1))
+	sets, err := s.store.Sets(ctx, userID, repository.SetFilter{MuscleIDs: only, From: from})
+	if err != nil {
+		return nil, err
+	}
+
+	names := s.muscleNamesByID()
+	type key struct{ week, muscle string }
+	totals := map[key]*models.WeeklyVolume{}
+	for _, set := range sets {
+		week := weekStart(set.PerformedAt).Format(time.DateOnly)
+		var groups []string
+		if muscle != "" {
+			groups = []string{muscle}
+		} else {
+			for _, id := range set.Muscles {
+				name, ok := names[id]
+				if !ok {
+					name = "muscle " + strconv.Itoa(id)
+				}
+				if !slices.Contains(groups, name) {
+					groups = append(groups, name)
+				}
+			}
+		}
+		for _, g := range groups {
+			k := key{week, g}
+			v, ok := totals[k]
+			if !ok {
+				v = &models.WeeklyVolume{WeekStart: week, Muscle: g}
+				totals[k] = v
+			}
+			v.Sets++
+			v.Reps += set.Reps
+			v.Volume += set.Weight * float64(set.Reps)
+		}
+	}
+
+	out := make([]models.WeeklyVolume, 0, len(totals))
+	for _, v := range totals {
+		out = append(out, *v)
+	}
+	slices.SortFunc(out, func(a, b models.WeeklyVolume) int {
+		return cmp.Or(cmp.Compare(a.WeekStart, b.WeekStart), cmp.Compare(a.Muscle, b.Muscle))
+	})
+	return out, nil
+}
+
+func (s *UserService) resolveMuscle(name string) ([]int, error) {
+	if s.muscles == nil {
+		return nil, fmt.Errorf("%w %q", models.ErrUnknownMuscle, name)
+	}
+	ids, ok := s.muscles.Resolve(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", models.ErrUnknownMuscle, name)
+	}
+	return ids, nil
+}
+
+// muscleNamesByID names single-muscle groups, e.g. 4 -> chest.
+func (s *UserService) muscleNamesByID() map[int]string {
+	names := map[int]string{}
+	if s.muscles == nil {
+		return names
+	}
+	for _, g := range s.muscles.Groups() {
+		if !g.Aggregate() {
+			names[g.IDs[0]] = g.Name
+		}
+	}
+	return names
+}
+
+// weekStart returns the Monday 00:00 UTC of t's week.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func recordValue(kind string, set models.SetLog) float64 {
+	switch kind {
+	case RecordE1RM:
+		return estimate1RM(set.Weight, set.Reps, set.RPE)
+	case RecordWeight:
+		return set.Weight
+	case RecordVolume:
+		return set.Weight * float64(set.Reps)
+	}
+	return 0
+}
+
+// recordsFor picks the best set per record kind; on ties the earliest set keeps the record.
+func recordsFor(sets []models.SetLog) map[string]models.PersonalRecord {
+	best := map[string]models.PersonalRecord{}
+	for _, set := range sets {
+		for _, kind := range []string{RecordE1RM, RecordWeight, RecordVolume} {
+			v := recordValue(kind, set)
+			cur, ok := best[kind]
+			if v <= 0 || ok && (v < cur.Value || v == cur.Value && !set.PerformedAt.Before(cur.PerformedAt)) {
+				continue
+			}
+			best[kind] = models.PersonalRecord{
+				ExerciseID:   set.ExerciseID,
+				ExerciseName: set.ExerciseName,
+				Kind:         kind,
+				Value:        v,
+				SetID:        set.ID,
+				Weight:       set.Weight,
+				Reps:         set.Reps,
+				PerformedAt:  set.PerformedAt,
+			}
+		}
+	}
+	return best
+}
+
+func recordList(m map[string]models.PersonalRecord) []models.PersonalRecord {
+	out := []models.PersonalRecord{}
+	for _, kind := range []string{RecordE1RM, RecordWeight, RecordVolume} {
+		if r, ok := m[kind]; ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
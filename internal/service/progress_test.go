@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEstimate1RM(t *testing.T) {
+	rpe8 := 8.0
+	tests := []struct {
+		weight float64
+		reps   int
+		rpe    *float64
+		want   float64
+	}{
+		{100, 1, nil, 100},
+		{100, 5, nil, 116.7},
+		{100, 3, &rpe8, 116.7},
+		{0, 10, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := estimate1RM(tt.weight, tt.reps, tt.rpe); got != tt.want {
+			t.Errorf("estimate1RM(%v, %d) = %v, want %v", tt.weight, tt.reps, got, tt.want)
+		}
+	}
+}
+
+func TestUserService_RecordsAndVolume(t *testing.T) {
+	ctx := context.Background()
+	db, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "progress.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	exercises, _ := repository.NewExerciseStore(ctx, db)
+	store, err := repository.NewUserStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exercises.UpsertExercises(ctx, []models.Exercise{
+		{ID: 192, Name: "Bench Press", Muscles: []int{4}, MusclesSecondary: []int{5}},
+		{ID: 88, Name: "Dips", Muscles: []int{4, 5}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewUserService(store).WithMuscles(NewMuscleRegistry(nil, jsonlog.New(io.Discard, jsonlog.LevelOff)))
+	u, err := store.CreateUser(ctx, "lifter@example.com", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	log := func(exercise int, weight float64, reps int, at time.Time) models.SetLog {
+		t.Helper()
+		set, err := svc.LogSet(ctx, u.ID, models.SetLog{ExerciseID: exercise, Weight: weight, Reps: reps, PerformedAt: at})
+		if err != nil {
+			t.Fatalf("LogSet: %v", err)
+		}
+		return set
+	}
+	if first := log(192, 80, 5, now.Add(-time.Hour)); first.Records != nil {
+		t.Fatalf("first set should not report records: %v", first.Records)
+	}
+	if got := log(192, 90, 3, now.Add(-30*time.Minute)).Records; !reflect.DeepEqual(got, []string{RecordE1RM, RecordWeight}) {
+		t.Fatalf("heavier triple: records %v", got)
+	}
+	// a single at RPE 8 counts like the triple: a tie breaks nothing
+	rpe := 8.0
+	single, err := svc.LogSet(ctx, u.ID, models.SetLog{ExerciseID: 192, Weight: 90, Reps: 1, RPE: &rpe, PerformedAt: now.Add(-20 * time.Minute)})
+	if err != nil || single.E1RM != 99 || single.Records != nil {
+		t.Fatalf("tied single: %+v %v", single, err)
+	}
+	log(88, 10, 10, now)
+
+	p, err := svc.ExerciseProgress(ctx, u.ID, 192)
+	if err != nil {
+		t.Fatalf("ExerciseProgress: %v", err)
+	}
+	if p.Sets != 3 || p.ExerciseName != "Bench Press" || len(p.Records) != 3 || p.Records[2].Value != 400 {
+		t.Fatalf("progress: %+v", p)
+	}
+
+	vol, err := svc.WeeklyVolume(ctx, u.ID, 1, "")
+	if err != nil {
+		t.Fatalf("WeeklyVolume: %v", err)
+	}
+	byMuscle := map[string]models.WeeklyVolume{}
+	for _, v := range vol {
+		byMuscle[v.Muscle] = v
+	}
+	// the bench sets and the dips count for chest; only the dips (primary triceps) for triceps
+	chest, _ := svc.muscles.Group("chest")
+	if byMuscle[chest.Name].Sets != 4 || byMuscle[chest.Name].Volume != 860 || byMuscle["triceps"].Sets != 1 {
+		t.Fatalf("volume: %+v", vol)
+	}
+
+	sets, err := svc.Sets(ctx, u.ID, SetQuery{Muscle: "triceps"})
+	if err != nil || len(sets) != 1 || sets[0].ExerciseID != 88 {
+		t.Fatalf("triceps history: %+v %v", sets, err)
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxTips caps how many ranked tips are attached to a response.
+const maxTips = 5
+
+// rulesCheckInterval is how often the rules file is checked for edits.
+const rulesCheckInterval = 5 * time.Second
+
+// bodyweightEquipment is wger's "none (bodyweight exercise)" equipment ID.
+const bodyweightEquipment = 7
+
+type rulesFile struct {
+	Rules []rule `yaml:"rules"`
+}
+
+// rule is one declarative advice rule: when every condition holds, its tip is emitted with its priority as score.
+type rule struct {
+	ID       string        `yaml:"id"`
+	Priority int           `yaml:"priority"`
+	When     ruleCondition `yaml:"when"`
+	Tip      string        `yaml:"tip"`
+	Why      string        `yaml:"why"`
+}
+
+type ruleCondition struct {
+	Muscles           []string         `yaml:"muscles"`
+	MinCount          *int             `yaml:"min_count"`
+	MaxCount          *int             `yaml:"max_count"`
+	MinCompoundRatio  *float64         `yaml:"min_compound_ratio"`
+	MaxCompoundRatio  *float64         `yaml:"max_compound_ratio"`
+	MinCategorySpread *int             `yaml:"min_category_spread"`
+	MaxCategorySpread *int             `yaml:"max_category_spread"`
+	MinEquipmentKinds *int             `yaml:"min_equipment_kinds"`
+	MaxEquipmentKinds *int             `yaml:"max_equipment_kinds"`
+	Equipment         []equipmentShare `yaml:"equipment"`
+}
+
+// equipmentShare bounds the fraction of exercises using an equipment ID (7 also covers exercises without equipment).
+type equipmentShare struct {
+	ID  int      `yaml:"id"`
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+}
+
+// exerciseMix is what rules look at.
+type exerciseMix struct {
+	muscles        []string
+	count          int
+	compoundRatio  float64
+	categorySpread int
+	equipmentKinds int
+	equipmentShare map[int]float64
+}
+
+// An exercise counts as compound when it works more than one muscle.
+func analyzeMix(muscles []string, exs []models.Exercise) exerciseMix {
+	m := exerciseMix{muscles: muscles, count: len(exs), equipmentShare: map[int]float64{}}
+	if len(exs) == 0 {
+		return m
+	}
+	categories := map[int]struct{}{}
+	compound := 0
+	uses := map[int]int{}
+	for _, e := range exs {
+		if len(e.Muscles)+len(e.MusclesSecondary) > 1 {
+			compound++
+		}
+		if e.Category != 0 {
+			categories[e.Category] = struct{}{}
+		}
+		if len(e.Equipment) == 0 {
+			uses[bodyweightEquipment]++
+		}
+		for _, id := range slices.Compact(slices.Sorted(slices.Values(e.Equipment))) {
+			uses[id]++
+		}
+	}
+	m.compoundRatio = float64(compound) / float64(len(exs))
+	m.categorySpread = len(categories)
+	m.equipmentKinds = len(uses)
+	for id, n := range uses {
+		m.equipmentShare[id] = float64(n) / float64(len(exs))
+	}
+	return m
+}
+
+// match checks every condition and returns the explanations of the ones that held.
+func (c ruleCondition) match(m exerciseMix) ([]string, bool) {
+	var why []string
+	check := func(ok bool, format string, args ...any) bool {
+		if ok {
+			why = append(why, fmt.Sprintf(format, args...))
+		}
+		return ok
+	}
+
+	if len(c.Muscles) > 0 {
+		found := ""
+		for _, name := range c.Muscles {
+			if slices.Contains(m.muscles, normalizeMuscle(name)) {
+				found = normalizeMuscle(name)
+				break
+			}
+		}
+		if !check(found != "", "muscle is %s", found) {
+			return nil, false
+		}
+	}
+	if c.MinCount != nil && !check(m.count >= *c.MinCount, "count %d >= %d", m.count, *c.MinCount) {
+		return nil, false
+	}
+	if c.MaxCount != nil && !check(m.count <= *c.MaxCount, "count %d <= %d", m.count, *c.MaxCount) {
+		return nil, false
+	}
+	if c.MinCompoundRatio != nil && !check(m.compoundRatio >= *c.MinCompoundRatio,
+		"compound ratio %.2f >= %.2f", m.compoundRatio, *c.MinCompoundRatio) {
+		return nil, false
+	}
+	if c.MaxCompoundRatio != nil && !check(m.compoundRatio <= *c.MaxCompoundRatio,
+		"compound ratio %.2f <= %.2f", m.compoundRatio, *c.MaxCompoundRatio) {
+		return nil, false
+	}
+	if c.MinCategorySpread != nil && !check(m.categorySpread >= *c.MinCategorySpread,
+		"category spread %d >= %d", m.categorySpread, *c.MinCategorySpread) {
+		return nil, false
+	}
+	if c.MaxCategorySpread != nil && !check(m.categorySpread <= *c.MaxCategorySpread,
+		"category spread %d <= %d", m.categorySpread, *c.MaxCategorySpread) {
+		return nil, false
+	}
+	if c.MinEquipmentKinds != nil && !check(m.equipmentKinds >= *c.MinEquipmentKinds,
+		"equipment kinds %d >= %d", m.equipmentKinds, *c.MinEquipmentKinds) {
+		return nil, false
+	}
+	if c.MaxEquipmentKinds != nil && !check(m.equipmentKinds <= *c.MaxEquipmentKinds,
+		"equipment kinds %d <= %d", m.equipmentKinds, *c.MaxEquipmentKinds) {
+		return nil, false
+	}
+	for _, eq := range c.Equipment {
+		share := m.equipmentShare[eq.ID]
+		if eq.Min != nil && !check(share >= *eq.Min, "equipment %d share %.2f >= %.2f", eq.ID, share, *eq.Min) {
+			return nil, false
+		}
+		if eq.Max != nil && !check(share <= *eq.Max, "equipment %d share %.2f <= %.2f", eq.ID, share, *eq.Max) {
+			return nil, false
+		}
+	}
+	return why, true
+}
+
+// defaultRules are the general rules of advice_rules.yaml, used when no rules file is available.
+// compound-heavy replaces the original "more than half have secondary muscles" heuristic.
+var defaultRules = []rule{
+	{
+		ID: "no-results", Priority: 100, When: ruleCondition{MaxCount: ptr(0)},
+		Tip: "Try broad compound movements and re-check your filters.",
+		Why: "Nothing matched the request.",
+	},
+	{
+		ID: "compound-heavy", Priority: 60, When: ruleCondition{MinCount: ptr(3), MinCompoundRatio: ptr(0.6)},
+		Tip: "Include specific warm-up sets and isolation moves before compounds.",
+		Why: "Most exercises work several muscles at once.",
+	},
+	{
+		ID: "balance", Priority: 10, When: ruleCondition{MinCount: ptr(1)},
+		Tip: "Balance compounds with accessory work; keep proper form.",
+		Why: "General guidance.",
+	},
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// RulesEngine ranks advice rules loaded from a YAML file. The file is re-read when it changes,
+// so rules can be edited without a redeploy; a broken edit keeps the last good rules.
+type RulesEngine struct {
+	path   string
+	logger *jsonlog.Logger
+
+	mu        sync.Mutex
+	rules     []rule
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewRulesEngine loads rules from path; an empty path (or an unreadable file) uses defaultRules.
+func NewRulesEngine(path string, logger *jsonlog.Logger) *RulesEngine {
+	e := &RulesEngine{path: path, logger: logger, rules: defaultRules}
+	if path != "" {
+		e.reload(true)
+	}
+	return e
+}
+
+// Evaluate returns up to maxTips matching tips, highest priority first. muscles are the requested
+// muscle's names (group name and aliases), so rules may use any of them.
+func (e *RulesEngine) Evaluate(muscles []string, exs []models.Exercise) []models.Tip {
+	rules := e.current()
+	names := make([]string, 0, len(muscles))
+	for _, m := range muscles {
+		names = append(names, normalizeMuscle(m))
+	}
+	mix := analyzeMix(names, exs)
+
+	var tips []models.Tip
+	for _, r := range rules {
+		why, ok := r.When.match(mix)
+		if !ok {
+			continue
+		}
+		tips = append(tips, models.Tip{
+			RuleID:  r.ID,
+			Text:    r.Tip,
+			Score:   r.Priority,
+			Reason:  r.Why,
+			Matched: why,
+		})
+	}
+	sort.SliceStable(tips, func(i, j int) bool { return tips[i].Score > tips[j].Score })
+	if len(tips) > maxTips {
+		tips = tips[:maxTips]
+	}
+	return tips
+}
+
+func (e *RulesEngine) current() []rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.path != "" && time.Since(e.checkedAt) >= rulesCheckInterval {
+		e.reload(false)
+	}
+	return e.rules
+}
+
+// reload re-reads the rules file when its modification time changed. Callers hold e.mu
+// (except the constructor).
+func (e *RulesEngine) reload(force bool) {
+	e.checkedAt = time.Now()
+	info, err := os.Stat(filepath.Clean(e.path))
+	if err != nil {
+		if force {
+			e.logger.PrintError("failed to read advice rules, using defaults", map[string]string{"path": e.path, "error": err.Error()})
+		}
+		return
+	}
+	if !force && info.ModTime().Equal(e.modTime) {
+		return
+	}
+	e.modTime = info.ModTime()
+
+	rules, err := loadRules(e.path)
+	if err != nil {
+		e.logger.PrintError("failed to load advice rules, keeping previous rules", map[string]string{"path": e.path, "error": err.Error()})
+		return
+	}
+	e.rules = rules
+	e.logger.PrintInfo("advice rules loaded", map[string]string{"path": e.path, "rules": fmt.Sprint(len(rules))})
+}
+
+func loadRules(path string) ([]rule, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var f rulesFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for i, r := range f.Rules {
+		if r.ID == "" || r.Tip == "" {
+			return nil, fmt.Errorf("rule #%d: id and tip are required", i+1)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", r.ID)
+		}
+		seen[r.ID] = true
+	}
+	if len(f.Rules) == 0 {
+		return nil, fmt.Errorf("no rules in %s", path)
+	}
+	return f.Rules, nil
+}
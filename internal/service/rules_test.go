@@ -0,0 +1,88 @@
+package service
+
+import (
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRulesEngine_RankAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(body string, mod time.Time) {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`rules:
+  - id: general
+    priority: 1
+    when: {min_count: 1}
+    tip: keep going
+  - id: chest-barbell
+    priority: 20
+    when:
+      muscles: [pecs]
+      equipment: [{id: 1, min: 0.5}]
+    tip: add dumbbells
+  - id: compound
+    priority: 10
+    when: {min_compound_ratio: 0.5}
+    tip: warm up
+`, time.Now().Add(-time.Hour))
+
+	e := NewRulesEngine(path, jsonlog.New(io.Discard, jsonlog.LevelOff))
+	exs := []models.Exercise{
+		{ID: 1, Muscles: []int{4}, MusclesSecondary: []int{5}, Equipment: []int{1}},
+		{ID: 2, Muscles: []int{4}, Equipment: []int{3}},
+	}
+	tips := e.Evaluate([]string{"chest", "pecs"}, exs)
+	var ids []string
+	for _, tip := range tips {
+		ids = append(ids, tip.RuleID)
+	}
+	if len(ids) != 3 || ids[0] != "chest-barbell" || ids[1] != "compound" || ids[2] != "general" {
+		t.Fatalf("ranking: got %v", ids)
+	}
+	if len(tips[0].Matched) != 2 {
+		t.Fatalf("explanation: got %v", tips[0].Matched)
+	}
+	if got := e.Evaluate([]string{"biceps"}, exs); len(got) != 2 {
+		t.Fatalf("muscle condition should not match biceps: %v", got)
+	}
+
+	// a broken edit keeps the last good rules; a valid one replaces them
+	write("rules: [", time.Now().Add(-30*time.Minute))
+	e.checkedAt = time.Time{}
+	if got := e.Evaluate([]string{"chest", "pecs"}, exs); len(got) != 3 {
+		t.Fatalf("broken file should keep rules: %v", got)
+	}
+	write("rules:\n  - {id: only, tip: hi}\n", time.Now())
+	e.checkedAt = time.Time{}
+	if got := e.Evaluate([]string{"chest", "pecs"}, exs); len(got) != 1 || got[0].RuleID != "only" {
+		t.Fatalf("reload: got %v", got)
+	}
+}
+
+func TestDefaultRules_MatchShippedFile(t *testing.T) {
+	shipped, err := loadRules("../../advice_rules.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]rule{}
+	for _, r := range shipped {
+		byID[r.ID] = r
+	}
+	for _, r := range defaultRules {
+		if got, ok := byID[r.ID]; !ok || !reflect.DeepEqual(got, r) {
+			t.Errorf("default rule %q differs from advice_rules.yaml: %+v", r.ID, got)
+		}
+	}
+}
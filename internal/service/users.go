@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"github.com/m4rk1sov/rbk-api/internal/repository"
+	"strconv"
+	"strings"
+)
+
+const (
+	// passwordIterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256.
+	passwordIterations = 600_000
+	passwordSaltBytes  = 16
+	passwordKeyBytes   = 32
+	// tokenPrefix marks API tokens so leaked ones are easy to recognise.
+	tokenPrefix = "rbk_"
+)
+
+// UserService handles accounts, API tokens and per-user data.
+type UserService struct {
+	store      *repository.UserStore
+	muscles    *MuscleRegistry
+	iterations int
+}
+
+func NewUserService(store *repository.UserStore) *UserService {
+	return &UserService{store: store, iterations: passwordIterations}
+}
+
+// Register creates an account. Emails are compared case-insensitively.
+func (s *UserService) Register(ctx context.Context, email, password string) (models.User, error) {
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.store.CreateUser(ctx, strings.TrimSpace(email), hash)
+}
+
+// Login checks the password and issues a new API token named tokenName.
+func (s *UserService) Login(ctx context.Context, email, password, tokenName string) (models.APIToken, error) {
+	u, hash, err := s.store.UserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, models.ErrNotFound) {
+		// burn the same time as a real check so response times don't reveal registered emails
+		_, _ = s.hashPassword(password)
+		return models.APIToken{}, models.ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	ok, err := verifyPassword(hash, password)
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	if !ok {
+		return models.APIToken{}, models.ErrInvalidCredentials
+	}
+	return s.IssueToken(ctx, u.ID, tokenName)
+}
+
+// IssueToken creates an API token; the secret is only returned here, the store keeps its SHA-256.
+func (s *UserService) IssueToken(ctx context.Context, userID int64, name string) (models.APIToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return models.APIToken{}, err
+	}
+	secret := tokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	t, err := s.store.CreateToken(ctx, userID, name, repository.HashToken(secret))
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	t.Token = secret
+	return t, nil
+}
+
+// Authenticate resolves an API token to its user.
+func (s *UserService) Authenticate(ctx context.Context, token string) (models.User, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return models.User{}, models.ErrInvalidCredentials
+	}
+	u, err := s.store.UserByToken(ctx, repository.HashToken(token))
+	if errors.Is(err, models.ErrNotFound) {
+		return models.User{}, models.ErrInvalidCredentials
+	}
+	return u, err
+}
+
+func (s *UserService) Tokens(ctx context.Context, userID int64) ([]models.APIToken, error) {
+	return s.store.Tokens(ctx, userID)
+}
+
+func (s *UserService) RevokeToken(ctx context.Context, userID, tokenID int64) error {
+	return s.store.DeleteToken(ctx, userID, tokenID)
+}
+
+func (s *UserService) SaveWorkout(ctx context.Context, userID int64, name string, w models.Workout) (models.SavedWorkout, error) {
+	return s.store.SaveWorkout(ctx, userID, name, w)
+}
+
+// UpdateWorkout replaces a saved workout and returns the stored version.
+func (s *UserService) UpdateWorkout(ctx context.Context, userID, id int64, name string, w models.Workout) (models.SavedWorkout, error) {
+	if err := s.store.UpdateWorkout(ctx, userID, id, name, w); err != nil {
+		return models.SavedWorkout{}, err
+	}
+	return s.store.Workout(ctx, userID, id)
+}
+
+func (s *UserService) Workout(ctx context.Context, userID, id int64) (models.SavedWorkout, error) {
+	return s.store.Workout(ctx, userID, id)
+}
+
+func (s *UserService) Workouts(ctx context.Context, userID int64) ([]models.SavedWorkout, error) {
+	return s.store.Workouts(ctx, userID)
+}
+
+func (s *UserService) DeleteWorkout(ctx context.Context, userID, id int64) error {
+	return s.store.DeleteWorkout(ctx, userID, id)
+}
+
+func (s *UserService) AddFavorite(ctx context.Context, userID int64, exerciseID int) error {
+	return s.store.AddFavorite(ctx, userID, exerciseID)
+}
+
+func (s *UserService) RemoveFavorite(ctx context.Context, userID int64, exerciseID int) error {
+	return s.store.RemoveFavorite(ctx, userID, exerciseID)
+}
+
+func (s *UserService) Favorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	return s.store.Favorites(ctx, userID)
+}
+
+// hashPassword returns "pbkdf2-sha256$<iterations>$<salt>$<key>" with base64 salt and key.
+func (s *UserService) hashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, s.iterations, passwordKeyBytes)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", s.iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func verifyPassword(encoded, password string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false, errors.New("unsupported password hash")
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash: %w", err)
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
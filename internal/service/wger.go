@@ -1,46 +1,99 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/m4rk1sov/rbk-api/internal/cache"
 	"github.com/m4rk1sov/rbk-api/internal/domain/models"
 	"github.com/m4rk1sov/rbk-api/internal/repository"
 	"github.com/m4rk1sov/rbk-api/pkg/jsonlog"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
 type FitnessService struct {
 	client         *repository.WgerClient
+	store          *repository.ExerciseStore
+	lookups        *Lookups
+	muscles        *MuscleRegistry
+	tips           AdviceProvider
+	rules          *RulesEngine
 	logger         *jsonlog.Logger
 	similarMuscles map[string][]string
-	cacheMu        sync.RWMutex
-	cache          map[string]cacheItem
-	ttl            time.Duration
+	cache          cache.Store[[]models.Exercise]
+	flight         *flightGroup[[]models.Exercise]
+	// ttl is how long entries are fresh; staleWhileRevalidate how long after that they are
+	// still served while refreshing in the background; staleIfError how long they remain a
+	// fallback when wger fails.
+	ttl                  time.Duration
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
 }
 
-type cacheItem struct {
-	expiresAt time.Time
-	data      []models.Exercise
-}
-
-func NewFitnessService(client *repository.WgerClient, logger *jsonlog.Logger, similarFile string) *FitnessService {
+// NewFitnessService wires the wger client with an optional SQLite store (nil disables the disk fallback).
+func NewFitnessService(client *repository.WgerClient, store *repository.ExerciseStore, logger *jsonlog.Logger, similarFile string) *FitnessService {
 	fs := &FitnessService{
-		client: client,
-		logger: logger,
-		cache:  make(map[string]cacheItem),
-		ttl:    5 * time.Minute,
+		client:  client,
+		store:   store,
+		lookups: NewLookups(client, store, logger),
+		muscles: NewMuscleRegistry(client, logger),
+		rules:   NewRulesEngine("", logger),
+		logger:  logger,
+		flight:  newFlightGroup[[]models.Exercise](30 * time.Second),
+
+		ttl:                  5 * time.Minute,
+		staleWhileRevalidate: time.Hour,
+		staleIfError:         24 * time.Hour,
 	}
+	fs.cache = cache.NewMemory[[]models.Exercise](defaultCacheEntries, fs.cacheMaxAge())
 	fs.similarMuscles = fs.loadSimilar(similarFile)
 	return fs
 }
 
+// defaultCacheEntries bounds the exercise cache unless WithCacheSize says otherwise.
+const defaultCacheEntries = 500
+
+// WithCacheSize replaces the exercise cache with an empty in-memory one holding at most n entries.
+func (s *FitnessService) WithCacheSize(n int) *FitnessService {
+	s.cache = cache.NewMemory[[]models.Exercise](n, s.cacheMaxAge())
+	return s
+}
+
+// WithCacheStore replaces the exercise cache with a shared backend such as cache.RESP.
+func (s *FitnessService) WithCacheStore(store cache.Store[[]models.Exercise]) *FitnessService {
+	s.cache = store
+	return s
+}
+
+// WithTips makes muscle-specific curated tips the advice attached to exercise responses.
+func (s *FitnessService) WithTips(p AdviceProvider) *FitnessService {
+	s.tips = p
+	return s
+}
+
+// WithRules replaces the built-in advice rules.
+func (s *FitnessService) WithRules(e *RulesEngine) *FitnessService {
+	s.rules = e
+	return s
+}
+
+// CacheMaxAge is how long cached exercises are worth keeping; remote backends should expire keys after it.
+func (s *FitnessService) CacheMaxAge() time.Duration {
+	return s.cacheMaxAge()
+}
+
+// Muscles exposes the muscle registry, e.g. to start its background sync.
+func (s *FitnessService) Muscles() *MuscleRegistry {
+	return s.muscles
+}
+
 func (s *FitnessService) loadSimilar(path string) map[string][]string {
 	if path == "" {
 		path = "./similar_muscles.json"
@@ -72,102 +125,254 @@ func (s *FitnessService) loadSimilar(path string) map[string][]string {
 	return m
 }
 
-// list of muscles
-var muscleNameToIDs = map[string][]int{
-	"biceps":     {1},
-	"shoulders":  {2},
-	"chest":      {4},
-	"triceps":    {5},
-	"abs":        {6},
-	"calves":     {7},
-	"glutes":     {8},
-	"quadriceps": {10},
-	"quads":      {10},
-	"hamstrings": {11},
-	"lats":       {12},
-	"lower back": {13},
-	"trapezius":  {14},
-	"forearms":   {9},
-	"neck":       {3},
-	"back":       {12, 13, 14}, // aggregate
+// ExerciseQuery pages the exercises returned for a muscle.
+type ExerciseQuery struct {
+	Limit  int
+	Offset int
+	// Category and Equipment keep exercises matching any of the IDs; ExcludeEquipment drops
+	// exercises using any of its IDs. Role narrows on how the requested muscle is worked.
+	Category         []int
+	Equipment        []int
+	ExcludeEquipment []int
+	Role             Role
+	// Sort orders the results before paging; empty means SortID.
+	Sort SortOrder
+	// Format renders descriptions; empty means sanitized HTML.
+	Format DescriptionFormat
+	// Language is a language code such as "de"; empty or unsupported means the server default.
+	Language string
+	// ExpandCategory and ExpandEquipment resolve wger IDs into {id, name} objects.
+	ExpandCategory  bool
+	ExpandEquipment bool
 }
 
+// fetchPageSize is the wger page size used when mirroring a muscle's full exercise set.
+const fetchPageSize = 100
+
 // GetExercisesByMuscle fetches exercises and returns a domain response with advice and similar groups.
-func (s *FitnessService) GetExercisesByMuscle(ctx context.Context, muscle string, limit int) (models.ExercisesResponse, error) {
+// The full set for the muscle is fetched (and cached) once; q selects the page that is returned.
+func (s *FitnessService) GetExercisesByMuscle(ctx context.Context, muscle string, q ExerciseQuery) (models.ExercisesResponse, error) {
 	muscleKey := strings.ToLower(strings.TrimSpace(muscle))
-	ids, ok := muscleNameToIDs[muscleKey]
-	if !ok {
-		// allow passing raw numeric id(s) comma-separated
-		if csvIDs, err := parseIDsCSV(muscleKey); err == nil && len(csvIDs) > 0 {
-			ids = csvIDs
-		} else {
-			return models.ExercisesResponse{}, errors.New("unknown muscle group; try one of: chest, back, biceps, triceps, shoulders, quads, hamstrings, calves, abs")
+	ids, err := s.resolveMuscle(muscleKey)
+	if err != nil {
+		return models.ExercisesResponse{}, err
+	}
+
+	f := q.pushdown()
+	language := s.languageFor(q.Language)
+	var set exerciseSet
+	served := language
+	if s.client.Schema() == repository.SchemaExerciseInfo {
+		// every translation comes with the exercise: cache once, localize per request
+		set, err = s.exercisesFor(ctx, muscleKey, ids, f)
+		set.data = localize(set.data, cmp.Or(language, s.client.Language()))
+	} else {
+		f.Language = language
+		set, err = s.exercisesFor(ctx, muscleKey, ids, f)
+		if english := s.languageFor("en"); language != english {
+			set, served, err = s.withEnglish(ctx, muscleKey, ids, f, english, set, err)
 		}
 	}
-	
-	cacheKey := cacheKeyFor(muscleKey, limit)
-	if data, ok := s.getCache(cacheKey); ok {
-		return models.ExercisesResponse{
-			Muscle:         muscleKey,
-			Exercises:      data,
-			SimilarMuscles: s.similarMuscles[muscleKey],
-			Advice:         s.makeAdvice(data),
-		}, nil
-	}
-	
-	data, err := s.client.FetchExercises(ctx, ids, limit)
 	if err != nil {
 		return models.ExercisesResponse{}, err
 	}
-	s.setCache(cacheKey, data)
-	
+	data := sortExercises(applyFilters(set.data, ids, q), ids, q.Sort)
+
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	start := min(q.Offset, len(data))
+	end := min(start+q.Limit, len(data))
+	tips := s.rules.Evaluate(s.muscleNames(muscleKey), data)
+
 	return models.ExercisesResponse{
 		Muscle:         muscleKey,
-		Exercises:      data,
+		Exercises:      renderDescriptions(s.lookups.Expand(ctx, data[start:end], q.ExpandCategory, q.ExpandEquipment), q.Format),
+		Total:          len(data),
+		Offset:         q.Offset,
+		Limit:          q.Limit,
 		SimilarMuscles: s.similarMuscles[muscleKey],
-		Advice:         s.makeAdvice(data),
+		Advice:         s.adviceFor(ctx, muscleKey, tips),
+		Tips:           tips,
+		Language:       repository.LanguageCode(cmp.Or(served, s.client.Language())),
+		Stale:          set.stale,
+		Partial:        set.partial,
+		Warnings:       set.warnings,
+		Age:            set.age,
 	}, nil
 }
 
-func (s *FitnessService) makeAdvice(exs []models.Exercise) string {
-	// Very simple heuristic advices:
-	if len(exs) == 0 {
-		return "Try broad compound movements and re-check your filters."
+// withEnglish adds the exercises wger has no translation for, which it leaves out of translated
+// lists, from the English set. When the translated fetch failed upstream, English is only taken
+// from the cache or store: a second live fetch would wait on the same outage. It also returns the
+// language the response is mostly in.
+func (s *FitnessService) withEnglish(ctx context.Context, muscleKey string, ids []int, f repository.ExerciseFilter, english int, set exerciseSet, err error) (exerciseSet, int, error) {
+	translated := f.Language
+	f.Language = english
+	if err != nil {
+		if !isUpstreamErr(err) {
+			return set, translated, err
+		}
+		fallback, ok := s.offline(ctx, muscleKey, ids, f)
+		if !ok {
+			return set, translated, err
+		}
+		return fallback, english, nil
+	}
+	fallback, err := s.exercisesFor(ctx, muscleKey, ids, f)
+	if err != nil {
+		s.logger.PrintError("failed to fetch English exercises", map[string]string{
+			"muscle": muscleKey,
+			"error":  err.Error(),
+		})
+		if len(set.data) == 0 {
+			return set, translated, err
+		}
+		set.partial = true
+		set.warnings = append(slices.Clip(set.warnings), "exercises without a translation are missing")
+		return set, translated, nil
+	}
+	if len(set.data) == 0 {
+		return fallback, english, nil
+	}
+	return mergeTranslations(set, fallback), translated, nil
+}
+
+// mergeTranslations adds the English exercises whose base has no translation in set.
+func mergeTranslations(set, english exerciseSet) exerciseSet {
+	have := make(map[int]bool, len(set.data))
+	for _, e := range set.data {
+		have[cmp.Or(e.BaseID, e.ID)] = true
+	}
+	data := slices.Clone(set.data)
+	for _, e := range english.data {
+		if !have[cmp.Or(e.BaseID, e.ID)] {
+			data = append(data, e)
+		}
+	}
+	slices.SortFunc(data, func(a, b models.Exercise) int { return cmp.Compare(a.ID, b.ID) })
+	return exerciseSet{
+		data:     data,
+		age:      max(set.age, english.age),
+		stale:    set.stale || english.stale,
+		partial:  set.partial || english.partial,
+		warnings: slices.Concat(set.warnings, english.warnings),
 	}
-	// If many exercises hit secondary muscles, suggest warm-up/isolation
-	secondaryHeavy := 0
-	for _, e := range exs {
-		if len(e.MusclesSecondary) > 0 {
-			secondaryHeavy++
+}
+
+// localize picks every exercise's text in language and trims translations to their names. The
+// cached exercises are left untouched.
+func localize(data []models.Exercise, language int) []models.Exercise {
+	out := make([]models.Exercise, len(data))
+	for i, e := range data {
+		e = repository.Localize(e, language)
+		if len(e.Translations) > 0 {
+			names := make([]models.Translation, len(e.Translations))
+			for j, t := range e.Translations {
+				names[j] = models.Translation{Language: t.Language, Name: t.Name}
+			}
+			e.Translations = names
 		}
+		out[i] = e
+	}
+	return out
+}
+
+func isUpstreamErr(err error) bool {
+	return errors.Is(err, models.ErrUpstreamUnavailable) || errors.Is(err, models.ErrUpstreamTimeout) ||
+		errors.Is(err, models.ErrUpstreamBadResponse)
+}
+
+// persist mirrors freshly fetched exercises into the store; failures are logged, not returned.
+func (s *FitnessService) persist(ctx context.Context, muscle string, data []models.Exercise) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.UpsertExercises(ctx, data); err != nil {
+		s.logger.PrintError("failed to store exercises", map[string]string{
+			"muscle": muscle,
+			"error":  err.Error(),
+		})
+	}
+}
+
+// fromStore answers from the SQLite mirror, reporting false when there is nothing to serve. The
+// time is when the oldest of the exercises was last refreshed.
+func (s *FitnessService) fromStore(ctx context.Context, muscle string, ids []int, limit int) ([]models.Exercise, time.Time, bool) {
+	if s.store == nil {
+		return nil, time.Time{}, false
+	}
+	data, updatedAt, err := s.store.ExercisesByMuscles(ctx, ids, limit)
+	if err != nil {
+		s.logger.PrintError("failed to read exercises from store", map[string]string{
+			"muscle": muscle,
+			"error":  err.Error(),
+		})
+		return nil, time.Time{}, false
 	}
-	if secondaryHeavy > len(exs)/2 {
-		return "Include specific warm-up sets and isolation moves before compounds."
+	return data, updatedAt, len(data) > 0
+}
+
+// muscleNames lists the names a muscle is known by, for matching advice rules.
+func (s *FitnessService) muscleNames(muscle string) []string {
+	if g, ok := s.muscles.Group(muscle); ok {
+		return append([]string{g.Name}, g.Aliases...)
 	}
-	return "Balance compounds with accessory work; keep proper form."
+	return []string{muscle}
 }
 
-func cacheKeyFor(muscle string, limit int) string {
-	return muscle + ":" + strconv.Itoa(limit)
+// adviceFor prefers a curated tip for the muscle and falls back to the top-ranked rule tip.
+func (s *FitnessService) adviceFor(ctx context.Context, muscle string, tips []models.Tip) string {
+	if s.tips != nil {
+		for _, name := range s.muscleNames(muscle) {
+			if tip, err := s.tips.Advice(ctx, name); err == nil {
+				return tip
+			}
+		}
+	}
+	if len(tips) > 0 {
+		return tips[0].Text
+	}
+	return ""
 }
 
-func (s *FitnessService) getCache(key string) ([]models.Exercise, bool) {
-	s.cacheMu.RLock()
-	defer s.cacheMu.RUnlock()
-	item, ok := s.cache[key]
-	if !ok || time.Now().After(item.expiresAt) {
-		return nil, false
+// resolveMuscle maps a muscle name, alias or comma-separated list of raw wger IDs to IDs.
+func (s *FitnessService) resolveMuscle(muscleKey string) ([]int, error) {
+	if ids, ok := s.muscles.Resolve(muscleKey); ok {
+		return ids, nil
 	}
-	return item.data, true
+	// allow passing raw numeric id(s) comma-separated
+	if !looksNumeric(muscleKey) {
+		return nil, fmt.Errorf("%w %q; try one of: chest, back, biceps, triceps, shoulders, quads, hamstrings, calves, abs", models.ErrUnknownMuscle, muscleKey)
+	}
+	ids, err := parseIDsCSV(muscleKey)
+	if err != nil || len(ids) == 0 {
+		return nil, fmt.Errorf("%w: %q is not a comma-separated list of positive IDs", models.ErrInvalidIDs, muscleKey)
+	}
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, fmt.Errorf("%w: %d is not a valid muscle id", models.ErrInvalidIDs, id)
+		}
+	}
+	return ids, nil
 }
 
-func (s *FitnessService) setCache(key string, data []models.Exercise) {
-	s.cacheMu.Lock()
-	defer s.cacheMu.Unlock()
-	s.cache[key] = cacheItem{
-		expiresAt: time.Now().Add(s.ttl),
-		data:      data,
+// looksNumeric reports whether s was meant as a list of IDs (digits, signs, commas and spaces only).
+func looksNumeric(s string) bool {
+	hasDigit := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r == ',' || r == ' ' || r == '-' || r == '+':
+		default:
+			return false
+		}
 	}
+	return hasDigit
 }
 
 func parseIDsCSV(s string) ([]int, error) {
@@ -189,12 +394,3 @@ func parseIDsCSV(s string) ([]int, error) {
 	}
 	return out, nil
 }
-
-func GetAvailableMuscles() []string {
-	names := make([]string, 0, len(muscleNameToIDs))
-	for name := range muscleNameToIDs {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-	return names
-}
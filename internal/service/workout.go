@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"github.com/m4rk1sov/rbk-api/internal/domain/models"
+	"slices"
+)
+
+// Level is the trainee's experience; it picks the set/rep/rest scheme.
+type Level string
+
+const (
+	LevelBeginner     Level = "beginner"
+	LevelIntermediate Level = "intermediate"
+	LevelAdvanced     Level = "advanced"
+)
+
+// ParseLevel validates an experience level; an empty value means LevelIntermediate.
+func ParseLevel(s string) (Level, bool) {
+	switch l := Level(s); l {
+	case "":
+		return LevelIntermediate, true
+	case LevelBeginner, LevelIntermediate, LevelAdvanced:
+		return l, true
+	}
+	return "", false
+}
+
+// WorkoutRequest describes the session to generate. Minutes is the session length including the
+// warm-up; an empty Equipment list means any equipment is available. Exercises whose primary muscles
+// include one of Avoid (e.g. muscles trained the day before) are left out.
+type WorkoutRequest struct {
+	Muscles   []string
+	Minutes   int
+	Equipment []int
+	Level     Level
+	Avoid     []string
+}
+
+type setScheme struct {
+	sets int
+	reps string
+	rest int // seconds
+}
+
+var schemes = map[Level]struct{ compound, accessory setScheme }{
+	LevelBeginner:     {compound: setScheme{3, "8-10", 120}, accessory: setScheme{2, "12-15", 60}},
+	LevelIntermediate: {compound: setScheme{4, "6-8", 150}, accessory: setScheme{3, "10-12", 75}},
+	LevelAdvanced:     {compound: setScheme{5, "4-6", 180}, accessory: setScheme{3, "8-12", 90}},
+}
+
+const (
+	// warmUpMinutes covers light cardio plus ramp-up sets.
+	warmUpMinutes = 8
+	// setWorkSeconds is the time spent on a set, excluding rest.
+	setWorkSeconds = 45
+	// compoundShare is the part of the working time given to compounds.
+	compoundShare = 0.6
+)
+
+// GenerateWorkout builds a session for the requested muscles: compounds first, then isolation
+// accessories for the targets and their synergists (from the similar muscles file), filling the
+// requested time. Exercises needing equipment that isn't available are skipped.
+func (s *FitnessService) GenerateWorkout(ctx context.Context, req WorkoutRequest) (models.Workout, error) {
+	scheme, ok := schemes[req.Level]
+	if !ok {
+		req.Level = LevelIntermediate
+		scheme = schemes[req.Level]
+	}
+	w := models.Workout{
+		Level:           string(req.Level),
+		DurationMinutes: req.Minutes,
+		WarmUp:          []models.WorkoutItem{},
+		Compounds:       []models.WorkoutItem{},
+		Accessories:     []models.WorkoutItem{},
+	}
+
+	avoid := map[int]bool{}
+	for _, m := range req.Avoid {
+		ids, _ := s.muscles.Resolve(normalizeMuscle(m))
+		for _, id := range ids {
+			avoid[id] = true
+		}
+	}
+
+	for _, m := range req.Muscles {
+		ids, _ := s.muscles.Resolve(normalizeMuscle(m))
+		for _, id := range ids {
+			delete(avoid, id)
+		}
+	}
+
+	var compounds, accessories [][]workoutCandidate
+	for _, m := range req.Muscles {
+		m = normalizeMuscle(m)
+		if slices.Contains(w.Muscles, m) {
+			continue
+		}
+		exs, stale, err := s.workoutExercises(ctx, m, req.Equipment, avoid)
+		if err != nil {
+			return models.Workout{}, err
+		}
+		w.Muscles = append(w.Muscles, m)
+		w.Stale = w.Stale || stale
+		c, a := splitCompound(m, exs)
+		compounds = append(compounds, c)
+		accessories = append(accessories, a)
+	}
+
+	// synergists only contribute accessories and are best effort
+	for _, m := range w.Muscles {
+		for _, syn := range s.similarMuscles[m] {
+			syn = normalizeMuscle(syn)
+			if slices.Contains(w.Muscles, syn) || slices.Contains(w.Synergists, syn) {
+				continue
+			}
+			ids, ok := s.muscles.Resolve(syn)
+			if !ok || slices.ContainsFunc(ids, func(id int) bool { return avoid[id] }) {
+				continue
+			}
+			exs, stale, err := s.workoutExercises(ctx, syn, req.Equipment, avoid)
+			if err != nil {
+				s.logger.PrintError("failed to load synergist exercises", map[string]string{"muscle": syn, "error": err.Error()})
+				continue
+			}
+			w.Synergists = append(w.Synergists, syn)
+			w.Stale = w.Stale || stale
+			_, a := splitCompound(syn, exs)
+			accessories = append(accessories, a)
+		}
+	}
+
+	budget := max(req.Minutes-warmUpMinutes, 0) * 60
+	used := map[int]bool{}
+	spent := 0
+	compoundBudget := int(float64(budget) * compoundShare)
+	w.Compounds = pickRoundRobin(compounds, scheme.compound, used, &spent, compoundBudget)
+	w.Accessories = pickRoundRobin(accessories, scheme.accessory, used, &spent, budget)
+
+	w.WarmUp = append(w.WarmUp, models.WorkoutItem{
+		Name: "Light cardio", Sets: 1, Reps: "5 min", Notes: "bike, rower or brisk walk",
+	})
+	if len(w.Compounds) > 0 {
+		first := w.Compounds[0]
+		w.WarmUp = append(w.WarmUp, models.WorkoutItem{
+			ExerciseID: first.ExerciseID, Name: first.Name, Muscle: first.Muscle,
+			Sets: 2, Reps: "10, 5", RestSeconds: 60, Notes: "ramp-up sets at about 40% and 60% of the working weight",
+		})
+	}
+	w.EstimatedMinutes = warmUpMinutes + (spent+59)/60
+	return w, nil
+}
+
+type workoutCandidate struct {
+	muscle   string
+	exercise models.Exercise
+}
+
+// workoutExercises returns the muscle's exercises that can be done with the available equipment
+// and don't primarily work an avoided muscle ID.
+func (s *FitnessService) workoutExercises(ctx context.Context, muscle string, equipment []int, avoid map[int]bool) ([]models.Exercise, bool, error) {
+	resp, err := s.GetExercisesByMuscle(ctx, muscle, ExerciseQuery{Limit: 100})
+	if err != nil {
+		return nil, false, err
+	}
+	out := make([]models.Exercise, 0, len(resp.Exercises))
+	for _, e := range resp.Exercises {
+		if (len(equipment) == 0 || availableWith(e, equipment)) && !slices.ContainsFunc(e.Muscles, func(id int) bool { return avoid[id] }) {
+			out = append(out, e)
+		}
+	}
+	return out, resp.Stale, nil
+}
+
+// availableWith reports whether every piece of equipment e needs is available; bodyweight always is.
+func availableWith(e models.Exercise, equipment []int) bool {
+	for _, id := range e.Equipment {
+		if id != bodyweightEquipment && !slices.Contains(equipment, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitCompound separates exercises working several muscles from isolation ones.
+func splitCompound(muscle string, exs []models.Exercise) (compound, isolation []workoutCandidate) {
+	for _, e := range exs {
+		c := workoutCandidate{muscle: muscle, exercise: e}
+		if len(e.Muscles)+len(e.MusclesSecondary) > 1 {
+			compound = append(compound, c)
+		} else {
+			isolation = append(isolation, c)
+		}
+	}
+	return compound, isolation
+}
+
+// pickRoundRobin takes one exercise per muscle in turn until spent reaches limit (seconds) or the
+// candidates run out. The first pick always fits so a short session still gets some work.
+func pickRoundRobin(groups [][]workoutCandidate, scheme setScheme, used map[int]bool, spent *int, limit int) []models.WorkoutItem {
+	cost := scheme.sets * (setWorkSeconds + scheme.rest)
+	items := []models.WorkoutItem{}
+	next := make([]int, len(groups))
+	for progress := true; progress; {
+		progress = false
+		for i, g := range groups {
+			for next[i] < len(g) && used[g[next[i]].exercise.ID] {
+				next[i]++
+			}
+			if next[i] == len(g) {
+				continue
+			}
+			if *spent+cost > limit && (len(items) > 0 || *spent > 0) {
+				return items
+			}
+			c := g[next[i]]
+			used[c.exercise.ID] = true
+			*spent += cost
+			items = append(items, models.WorkoutItem{
+				ExerciseID:  c.exercise.ID,
+				Name:        c.exercise.Name,
+				Muscle:      c.muscle,
+				Sets:        scheme.sets,
+				Reps:        scheme.reps,
+				RestSeconds: scheme.rest,
+			})
+			progress = true
+		}
+	}
+	return items
+}
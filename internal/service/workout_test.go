@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGenerateWorkout(t *testing.T) {
+	svc := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("muscles") {
+		case "4": // chest
+			_, _ = io.WriteString(w, `{"count":4,"results":[
+				{"id":1,"name":"Bench Press","muscles":[4],"muscles_secondary":[5],"equipment":[1,8]},
+				{"id":2,"name":"Push-up","muscles":[4],"muscles_secondary":[5,2],"equipment":[7]},
+				{"id":3,"name":"Cable Fly","muscles":[4],"equipment":[6]},
+				{"id":4,"name":"Dumbbell Fly","muscles":[4],"equipment":[3,8]}]}`)
+		case "5": // triceps
+			_, _ = io.WriteString(w, `{"count":2,"results":[
+				{"id":2,"name":"Push-up","muscles":[4],"muscles_secondary":[5,2],"equipment":[7]},
+				{"id":5,"name":"Skull Crusher","muscles":[5],"equipment":[3,8]}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"count":0,"results":[]}`)
+		}
+	}))
+	svc.similarMuscles = map[string][]string{"chest": {"triceps", "unknown muscle"}}
+
+	w, err := svc.GenerateWorkout(context.Background(), WorkoutRequest{
+		Muscles:   []string{"chest"},
+		Minutes:   60,
+		Equipment: []int{3, 8},
+		Level:     LevelBeginner,
+	})
+	if err != nil {
+		t.Fatalf("GenerateWorkout: %v", err)
+	}
+	// the barbell bench press and the cable fly need equipment that isn't available
+	if len(w.Compounds) != 1 || w.Compounds[0].ExerciseID != 2 || w.Compounds[0].Sets != 3 {
+		t.Fatalf("compounds: %+v", w.Compounds)
+	}
+	var accessories []int
+	for _, it := range w.Accessories {
+		accessories = append(accessories, it.ExerciseID)
+	}
+	if len(accessories) != 2 || accessories[0] != 4 || accessories[1] != 5 {
+		t.Fatalf("accessories: %v", accessories)
+	}
+	if len(w.Synergists) != 1 || w.Synergists[0] != "triceps" {
+		t.Fatalf("synergists: %v", w.Synergists)
+	}
+	if len(w.WarmUp) != 2 || w.EstimatedMinutes > 60 {
+		t.Fatalf("warm-up %d items, estimated %d min", len(w.WarmUp), w.EstimatedMinutes)
+	}
+
+	// avoided muscles are matched by ID, however they are spelled
+	w, err = svc.GenerateWorkout(context.Background(), WorkoutRequest{
+		Muscles: []string{"chest"},
+		Avoid:   []string{"Triceps "},
+		Minutes: 60,
+	})
+	if err != nil || len(w.Synergists) != 0 {
+		t.Fatalf("avoided synergist: %v %v", w.Synergists, err)
+	}
+
+	if _, err := svc.GenerateWorkout(context.Background(), WorkoutRequest{Muscles: []string{"wings"}, Minutes: 30}); err == nil {
+		t.Fatal("expected unknown muscle error")
+	}
+}
@@ -3,12 +3,11 @@ package util
 import (
 	"encoding/json"
 	"net/http"
-	"regexp"
 )
 
+// StripHTML returns the plain text of an HTML fragment; see HTMLToText.
 func StripHTML(input string) string {
-	re := regexp.MustCompile(`<[^>]*>`)
-	return re.ReplaceAllString(input, "")
+	return HTMLToText(input)
 }
 
 func WriteJSON(w http.ResponseWriter, status int, v any) {
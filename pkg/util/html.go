@@ -0,0 +1,357 @@
+package util
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// htmlNode is an element of a sanitized fragment; text nodes have no tag.
+type htmlNode struct {
+	tag      string
+	href     string
+	text     string
+	children []*htmlNode
+}
+
+// allowedTags is the sanitizer whitelist. Other tags are dropped but their text is kept,
+// except for droppedTags whose content is removed as well.
+var allowedTags = map[string]bool{
+	"p": true, "br": true, "ul": true, "ol": true, "li": true,
+	"strong": true, "b": true, "em": true, "i": true, "u": true, "a": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "blockquote": true,
+}
+
+var droppedTags = map[string]bool{"script": true, "style": true, "iframe": true, "object": true, "template": true}
+
+var blockTags = map[string]bool{
+	"p": true, "ul": true, "ol": true, "li": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+var (
+	tagPattern  = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:\s+[^\s=/>]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+))?)*)\s*/?>`)
+	hrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	spaces      = regexp.MustCompile(`\s+`)
+	blankLines  = regexp.MustCompile(`\n{3,}`)
+)
+
+// parseHTML reads a fragment into a tree holding only whitelisted elements. It is forgiving the way
+// wger content needs: unknown and stray closing tags are ignored and open elements close at the end.
+func parseHTML(s string) *htmlNode {
+	root := &htmlNode{}
+	stack := []*htmlNode{root}
+	top := func() *htmlNode { return stack[len(stack)-1] }
+	closeTag := func(tag string) {
+		for i := len(stack) - 1; i > 0; i-- {
+			if stack[i].tag == tag {
+				stack = stack[:i]
+				return
+			}
+		}
+	}
+	text := func(t string) {
+		if t != "" {
+			top().children = append(top().children, &htmlNode{text: html.UnescapeString(t)})
+		}
+	}
+
+	for s != "" {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			text(s)
+			break
+		}
+		text(s[:lt])
+		s = s[lt:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				return root
+			}
+			s = s[end+3:]
+			continue
+		case strings.HasPrefix(s, "<!"), strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return root
+			}
+			s = s[end+1:]
+			continue
+		}
+		m := tagPattern.FindStringSubmatch(s)
+		if m == nil {
+			text("<")
+			s = s[1:]
+			continue
+		}
+		s = s[len(m[0]):]
+		closing, tag := m[1] == "/", strings.ToLower(m[2])
+
+		if droppedTags[tag] {
+			if !closing {
+				if end := strings.Index(strings.ToLower(s), "</"+tag); end >= 0 {
+					s = s[end:]
+				} else {
+					s = ""
+				}
+			}
+			continue
+		}
+		if !allowedTags[tag] {
+			continue
+		}
+		if closing {
+			closeTag(tag)
+			continue
+		}
+		// a new block implicitly ends an open paragraph, and a new item the previous one
+		if blockTags[tag] && top().tag == "p" {
+			closeTag("p")
+		}
+		if tag == "li" {
+			for i := len(stack) - 1; i > 0 && stack[i].tag != "ul" && stack[i].tag != "ol"; i-- {
+				if stack[i].tag == "li" {
+					stack = stack[:i]
+					break
+				}
+			}
+		}
+		n := &htmlNode{tag: tag}
+		if tag == "a" {
+			n.href = safeHref(m[3])
+		}
+		top().children = append(top().children, n)
+		if tag != "br" {
+			stack = append(stack, n)
+		}
+	}
+	return root
+}
+
+// safeHref returns the link target of an <a> tag's attributes, or "" unless it is http(s) or mailto.
+func safeHref(attrs string) string {
+	m := hrefPattern.FindStringSubmatch(attrs)
+	if m == nil {
+		return ""
+	}
+	href := strings.TrimSpace(html.UnescapeString(m[1] + m[2] + m[3]))
+	lower := strings.ToLower(href)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:") {
+		return href
+	}
+	return ""
+}
+
+// SanitizeHTML keeps only whitelisted tags (paragraphs, lists, emphasis, headings, http links),
+// drops every other attribute and script-like content, and normalizes entities and whitespace.
+func SanitizeHTML(s string) string {
+	var sb strings.Builder
+	var write func(n *htmlNode)
+	write = func(n *htmlNode) {
+		switch {
+		case n.tag == "" && n.children == nil:
+			sb.WriteString(html.EscapeString(spaces.ReplaceAllString(n.text, " ")))
+			return
+		case n.tag == "br":
+			sb.WriteString("<br>")
+			return
+		case n.tag == "a" && n.href != "":
+			sb.WriteString(`<a href="` + html.EscapeString(n.href) + `" rel="nofollow">`)
+		case n.tag != "" && (n.tag != "a"):
+			sb.WriteString("<" + n.tag + ">")
+		}
+		for _, c := range n.children {
+			write(c)
+		}
+		if n.tag != "" && (n.tag != "a" || n.href != "") {
+			sb.WriteString("</" + n.tag + ">")
+		}
+	}
+	write(parseHTML(s))
+	return strings.TrimSpace(sb.String())
+}
+
+// HTMLToText renders an HTML fragment as plain text: paragraphs are separated by blank lines,
+// list items start with "- " or "1. ", entities are decoded.
+func HTMLToText(s string) string {
+	return render(parseHTML(s), false)
+}
+
+// HTMLToMarkdown renders an HTML fragment as Markdown, keeping lists (nested ones indented),
+// emphasis, headings and links.
+func HTMLToMarkdown(s string) string {
+	return render(parseHTML(s), true)
+}
+
+func render(root *htmlNode, markdown bool) string {
+	r := renderer{markdown: markdown}
+	out := strings.Join(r.blocks(root.children), "\n\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(out, "\n\n"))
+}
+
+type renderer struct {
+	markdown bool
+}
+
+// blocks renders nodes as a list of blocks, gathering runs of inline content into paragraphs.
+func (r renderer) blocks(nodes []*htmlNode) []string {
+	var out []string
+	var inline strings.Builder
+	flush := func() {
+		if p := tidyLines(inline.String()); p != "" {
+			out = append(out, p)
+		}
+		inline.Reset()
+	}
+	for _, n := range nodes {
+		if !blockTags[n.tag] {
+			inline.WriteString(r.inline(n))
+			continue
+		}
+		flush()
+		if b := r.block(n); b != "" {
+			out = append(out, b)
+		}
+	}
+	flush()
+	return out
+}
+
+func (r renderer) block(n *htmlNode) string {
+	switch n.tag {
+	case "ul", "ol":
+		return r.list(n)
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := tidyLines(r.inlines(n.children))
+		if text == "" || !r.markdown {
+			return text
+		}
+		level, _ := strconv.Atoi(n.tag[1:])
+		return strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\n", " ")
+	case "blockquote":
+		text := strings.Join(r.blocks(n.children), "\n\n")
+		if !r.markdown {
+			return text
+		}
+		return prefixLines(text, "> ", "> ")
+	default: // p, or an li outside a list
+		return strings.Join(r.blocks(n.children), "\n\n")
+	}
+}
+
+// list renders a list as one tight block; item continuation lines are indented under the marker.
+func (r renderer) list(n *htmlNode) string {
+	var items []string
+	i := 0
+	for _, c := range n.children {
+		if c.tag != "li" {
+			// text between items is usually whitespace; anything else becomes an item of its own
+			if strings.TrimSpace(r.inline(c)) == "" && !blockTags[c.tag] {
+				continue
+			}
+			c = &htmlNode{tag: "li", children: []*htmlNode{c}}
+		}
+		body := strings.Join(r.blocks(c.children), "\n")
+		if body == "" {
+			continue
+		}
+		i++
+		marker := "- "
+		if n.tag == "ol" {
+			marker = strconv.Itoa(i) + ". "
+		}
+		items = append(items, prefixLines(body, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (r renderer) inlines(nodes []*htmlNode) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		sb.WriteString(r.inline(n))
+	}
+	return sb.String()
+}
+
+func (r renderer) inline(n *htmlNode) string {
+	switch n.tag {
+	case "":
+		text := spaces.ReplaceAllString(n.text, " ")
+		if r.markdown {
+			text = escapeMarkdown(text)
+		}
+		return text
+	case "br":
+		return "\n"
+	}
+	inner := r.inlines(n.children)
+	if blockTags[n.tag] {
+		// only reached for blocks nested in inline elements, e.g. <b><p>…</p></b>
+		inner = "\n" + strings.Join(r.blocks(n.children), "\n\n") + "\n"
+	}
+	if !r.markdown || strings.TrimSpace(inner) == "" {
+		return inner
+	}
+	switch n.tag {
+	case "strong", "b":
+		return wrapInline(inner, "**")
+	case "em", "i":
+		return wrapInline(inner, "_")
+	case "a":
+		if n.href != "" {
+			return "[" + strings.TrimSpace(inner) + "](" + markdownHref.Replace(n.href) + ")"
+		}
+	}
+	return inner
+}
+
+// wrapInline puts the marker around inner, keeping surrounding spaces outside so the Markdown stays valid.
+func wrapInline(inner, marker string) string {
+	trimmed := strings.TrimSpace(inner)
+	lead := inner[:strings.Index(inner, trimmed)]
+	trail := inner[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+// markdownEscaper escapes emphasis, code and link syntax, and <, > and & so decoded entities can't
+// turn into raw HTML in the rendered Markdown.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "&", `\&`)
+
+// markdownHref percent-encodes what could end a link destination early and smuggle in another link.
+var markdownHref = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20", "<", "%3C", ">", "%3E", `\`, "%5C")
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// tidyLines trims every line, collapses inner runs of spaces and drops empty lines.
+func tidyLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, l := range lines {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			out = append(out, l)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + l
+		case l != "":
+			lines[i] = rest + l
+		default:
+			lines[i] = strings.TrimRight(rest, " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
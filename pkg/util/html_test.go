@@ -0,0 +1,89 @@
+package util
+
+import "testing"
+
+// samples are descriptions as served by wger's /exercise/ endpoint.
+var samples = map[string]string{
+	"bench press": "<p>Lay down on a bench, the bar should be directly above your eyes, the knees are somewhat angled " +
+		"and the feet are firmly on the floor.</p>\n<p>If you train with a high weight it is advisable to have a " +
+		"<em>spotter</em> that can help you up if you can&#39;t lift the weight on your own.</p>",
+	"ordered steps": "<p>Stand with your feet shoulder&nbsp;width apart.</p>\n<ol>\n<li>Hinge at the hips&nbsp;</li>\n" +
+		"<li>Keep your back <strong>straight</strong></li>\n<li>Drive through the heels</li>\n</ol>\n<p>&nbsp;</p>",
+	"nested list":   "<ul>\n<li>Setup<ul><li>Grip the bar</li><li>Brace</li></ul></li>\n<li>Lift</li>\n</ul>",
+	"unclosed tags": "<p>Pull-ups &amp; chin-ups<p>Use a <b>pronated grip<li>stray item",
+	"attributes": `<p style="margin: 0" class="x" onclick="alert(1)">Curl the <a href="https://wger.de/" target="_blank">bar</a> ` +
+		`or <a href="javascript:alert(1)">not</a>.</p>`,
+	"script": "<p>Squat</p><script>alert('x')</script><style>p{}</style><!-- note --><p>3 &lt; 5 * 2</p>",
+	"plain":  "  Keep your elbows\n\n   tucked in.  ",
+	// escaped markup and link targets that try to break out of the Markdown link syntax
+	"escaped html":   "<p>Caption: &lt;img src=x onerror=alert(1)&gt; &amp; more</p>",
+	"link injection": `<p><a href="https://x/)[y](javascript:alert(1)">go</a></p>`,
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		sample string
+		want   string
+	}{
+		{"bench press", "<p>Lay down on a bench, the bar should be directly above your eyes, the knees are somewhat angled " +
+			"and the feet are firmly on the floor.</p> <p>If you train with a high weight it is advisable to have a " +
+			"<em>spotter</em> that can help you up if you can&#39;t lift the weight on your own.</p>"},
+		{"unclosed tags", "<p>Pull-ups &amp; chin-ups</p><p>Use a <b>pronated grip<li>stray item</li></b></p>"},
+		{"attributes", `<p>Curl the <a href="https://wger.de/" rel="nofollow">bar</a> or not.</p>`},
+		{"script", "<p>Squat</p><p>3 &lt; 5 * 2</p>"},
+		{"escaped html", "<p>Caption: &lt;img src=x onerror=alert(1)&gt; &amp; more</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sample, func(t *testing.T) {
+			if got := SanitizeHTML(samples[tt.sample]); got != tt.want {
+				t.Fatalf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		sample string
+		want   string
+	}{
+		{"bench press", "Lay down on a bench, the bar should be directly above your eyes, the knees are somewhat angled " +
+			"and the feet are firmly on the floor.\n\nIf you train with a high weight it is advisable to have a " +
+			"spotter that can help you up if you can't lift the weight on your own."},
+		{"ordered steps", "Stand with your feet shoulder width apart.\n\n1. Hinge at the hips\n2. Keep your back straight\n3. Drive through the heels"},
+		{"nested list", "- Setup\n  - Grip the bar\n  - Brace\n- Lift"},
+		{"script", "Squat\n\n3 < 5 * 2"},
+		{"plain", "Keep your elbows tucked in."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sample, func(t *testing.T) {
+			if got := HTMLToText(samples[tt.sample]); got != tt.want {
+				t.Fatalf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		sample string
+		want   string
+	}{
+		{"bench press", "Lay down on a bench, the bar should be directly above your eyes, the knees are somewhat angled " +
+			"and the feet are firmly on the floor.\n\nIf you train with a high weight it is advisable to have a " +
+			"_spotter_ that can help you up if you can't lift the weight on your own."},
+		{"ordered steps", "Stand with your feet shoulder width apart.\n\n1. Hinge at the hips\n2. Keep your back **straight**\n3. Drive through the heels"},
+		{"nested list", "- Setup\n  - Grip the bar\n  - Brace\n- Lift"},
+		{"attributes", "Curl the [bar](https://wger.de/) or not."},
+		{"script", "Squat\n\n3 \\< 5 \\* 2"},
+		{"escaped html", "Caption: \\<img src=x onerror=alert(1)\\> \\& more"},
+		{"link injection", "[go](https://x/%29[y]%28javascript:alert%281%29)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sample, func(t *testing.T) {
+			if got := HTMLToMarkdown(samples[tt.sample]); got != tt.want {
+				t.Fatalf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}